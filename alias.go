@@ -0,0 +1,24 @@
+package s3region
+
+import "strings"
+
+// resolveAlias expands input if it is an alias, or an alias followed by
+// "/path", into the aliased identifier. It returns the alias name and
+// reports false if input is not an alias.
+func (c *config) resolveAlias(input string) (target, alias string, ok bool) {
+	name, path, hasPath := strings.Cut(input, "/")
+	target, ok = c.aliases[name]
+	if !ok {
+		return "", "", false
+	}
+	if hasPath {
+		target = strings.TrimSuffix(target, "/") + "/" + path
+	}
+	return target, name, true
+}
+
+// withoutAliases returns opts with alias expansion disabled, so that an
+// aliased identifier is not expanded again.
+func withoutAliases(opts []Option) []Option {
+	return append(opts[:len(opts):len(opts)], WithAliases(nil))
+}
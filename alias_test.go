@@ -0,0 +1,72 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	aliases := WithAliases(map[string]string{
+		"prod-logs": "acme-prod-logs-7f3a2c-us-east-1",
+		"lake":      "s3://acme-data-lake/warehouse/",
+		"loop":      "loop",
+	})
+
+	tests := []struct {
+		input      string
+		wantBucket string
+		wantKey    string
+		wantAlias  string
+	}{
+		{"prod-logs", "acme-prod-logs-7f3a2c-us-east-1", "", "prod-logs"},
+		{"prod-logs/2026/10/app.log", "acme-prod-logs-7f3a2c-us-east-1", "2026/10/app.log", "prod-logs"},
+		{"lake/events", "acme-data-lake", "warehouse/events", "lake"},
+		{"other-bucket/key", "other-bucket", "key", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ref, err := Parse(tt.input, aliases)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if ref.Bucket != tt.wantBucket || ref.Key != tt.wantKey || ref.Alias != tt.wantAlias {
+				t.Errorf("Parse() = %q, %q, alias %q, want %q, %q, alias %q",
+					ref.Bucket, ref.Key, ref.Alias, tt.wantBucket, tt.wantKey, tt.wantAlias)
+			}
+			if ref.Input != tt.input {
+				t.Errorf("Input = %q, want %q", ref.Input, tt.input)
+			}
+		})
+	}
+
+	client := &mockHTTPClient{region: "us-east-1"}
+	region, err := GetBucketRegion(context.Background(), "prod-logs", aliases, WithHTTPClient(client))
+	if err != nil || region != "us-east-1" {
+		t.Errorf("GetBucketRegion() = %q, %v, want %q", region, err, "us-east-1")
+	}
+
+	// An alias expanding to itself is expanded only once
+	client = &mockHTTPClient{region: "us-east-1"}
+	if _, err := GetBucketRegion(context.Background(), "loop", aliases, WithHTTPClient(client)); err != nil || !client.called {
+		t.Errorf("GetBucketRegion(loop) error = %v, called = %v, want bucket lookup", err, client.called)
+	}
+}
+
+func TestAliasErrorKeepsInput(t *testing.T) {
+	aliases := WithAliases(map[string]string{"broken": "s3://Not_A_Bucket/key"})
+
+	_, err := GetBucketRegion(context.Background(), "broken", aliases)
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("GetBucketRegion() error = %v, want *Error", err)
+	}
+	if e.Input != "broken" || e.BucketName != "Not_A_Bucket" {
+		t.Errorf("Error Input = %q, BucketName = %q, want %q, %q", e.Input, e.BucketName, "broken", "Not_A_Bucket")
+	}
+	if !errors.Is(err, ErrInvalidBucketName) || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("GetBucketRegion() error = %v", err)
+	}
+}
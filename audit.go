@@ -0,0 +1,84 @@
+package s3region
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Exposure classifies the anonymous access to a bucket found by AuditBucket.
+type Exposure int
+
+const (
+	ExposurePrivate    Exposure = iota // Anonymous requests are denied
+	ExposurePublicRead                 // Objects can be read anonymously, but not listed
+	ExposurePublicList                 // Anyone can list the bucket's objects
+)
+
+// String returns the name of the exposure, such as "public-list".
+func (e Exposure) String() string {
+	switch e {
+	case ExposurePublicRead:
+		return "public-read"
+	case ExposurePublicList:
+		return "public-list"
+	}
+	return "private"
+}
+
+// Audit is the outcome of AuditBucket.
+type Audit struct {
+	*Result           // Region lookup the audit built on
+	Exposure Exposure // Anonymous access to the bucket
+
+	ListEndpoint   string // URL of the ListObjectsV2 request
+	ListStatusCode int    // HTTP status of the ListObjectsV2 request
+}
+
+// AuditBucket accepts any S3 identifier format accepted by GetBucketRegion
+// and reports whether the bucket allows anonymous ListObjectsV2. After the
+// region lookup, it sends an anonymous GET ?list-type=2&max-keys=0 to the
+// bucket's regional endpoint, so that no object is listed.
+//
+// A bucket that denies the listing is ExposurePublicRead if it answered the
+// anonymous lookup with 200, or if the input names an object that can be
+// read anonymously, and ExposurePrivate otherwise.
+func AuditBucket(ctx context.Context, input string, opts ...Option) (*Audit, error) {
+	const op = "AuditBucket"
+
+	res, err := lookupInput(ctx, op, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	if res.Bucket == "" {
+		return nil, newError(op, "", input, ErrNoBucket)
+	}
+
+	cfg := newConfig(opts)
+	url := endpointURL(res.Bucket, "", res.Region) + "/?list-type=2&max-keys=0"
+	resp, err := send(ctx, cfg, http.MethodGet, url)
+	if err != nil {
+		return nil, newError(op, res.Bucket, input, err)
+	}
+	audit := &Audit{Result: res, ListEndpoint: url, ListStatusCode: resp.status}
+	switch resp.status {
+	case http.StatusOK:
+		audit.Exposure = ExposurePublicList
+		return audit, nil
+	case http.StatusForbidden:
+	default:
+		return nil, newError(op, res.Bucket, input, resp.error(statusErrorClass(resp.status)))
+	}
+
+	if res.Access == AccessPublic {
+		audit.Exposure = ExposurePublicRead
+		return audit, nil
+	}
+	if res.Key != "" && !strings.HasSuffix(res.Key, "/") {
+		resp, err := send(ctx, cfg, http.MethodHead, endpointURL(res.Bucket, res.Key, res.Region))
+		if err == nil && resp.status == http.StatusOK {
+			audit.Exposure = ExposurePublicRead
+		}
+	}
+	return audit, nil
+}
@@ -0,0 +1,103 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAuditBucket(t *testing.T) {
+	region := map[string]string{"x-amz-bucket-region": "eu-west-1"}
+	listURL := "GET https://my-bucket.s3.eu-west-1.amazonaws.com/?list-type=2&max-keys=0"
+	objectURL := "HEAD https://my-bucket.s3.eu-west-1.amazonaws.com/data/file.csv"
+
+	tests := []struct {
+		name     string
+		input    string
+		routes   map[string]stubResponse
+		want     Exposure
+		wantErr  error
+		wantList int
+	}{
+		{
+			name:     "public list",
+			input:    "my-bucket",
+			routes:   map[string]stubResponse{headURL: {200, region, ""}, listURL: {200, nil, "<ListBucketResult/>"}},
+			want:     ExposurePublicList,
+			wantList: 200,
+		},
+		{
+			name:     "private",
+			input:    "s3://my-bucket",
+			routes:   map[string]stubResponse{headURL: {403, region, ""}, listURL: {403, nil, ""}},
+			want:     ExposurePrivate,
+			wantList: 403,
+		},
+		{
+			name:     "public read from bucket",
+			input:    "my-bucket",
+			routes:   map[string]stubResponse{headURL: {200, region, ""}, listURL: {403, nil, ""}},
+			want:     ExposurePublicRead,
+			wantList: 403,
+		},
+		{
+			name:  "public read from object",
+			input: "s3://my-bucket/data/file.csv",
+			routes: map[string]stubResponse{
+				headURL:   {403, region, ""},
+				listURL:   {403, nil, ""},
+				objectURL: {200, nil, ""},
+			},
+			want:     ExposurePublicRead,
+			wantList: 403,
+		},
+		{
+			name:  "private object",
+			input: "s3://my-bucket/data/file.csv",
+			routes: map[string]stubResponse{
+				headURL:   {403, region, ""},
+				listURL:   {403, nil, ""},
+				objectURL: {403, nil, ""},
+			},
+			want:     ExposurePrivate,
+			wantList: 403,
+		},
+		{
+			name:    "not found",
+			input:   "my-bucket",
+			routes:  map[string]stubResponse{headURL: {404, nil, ""}},
+			wantErr: ErrBucketNotFound,
+		},
+		{
+			name:    "access point",
+			input:   "https://my-ap-123456789012.s3-accesspoint.us-west-2.amazonaws.com/key",
+			wantErr: ErrNoBucket,
+		},
+		{
+			name:    "list throttled",
+			input:   "my-bucket",
+			routes:  map[string]stubResponse{headURL: {200, region, ""}, listURL: {503, nil, ""}},
+			wantErr: ErrThrottled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &routeClient{routes: tt.routes}
+			audit, err := AuditBucket(context.Background(), tt.input, WithHTTPClient(client))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("AuditBucket() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuditBucket() error = %v", err)
+			}
+			if audit.Exposure != tt.want || audit.ListStatusCode != tt.wantList || audit.Region != "eu-west-1" {
+				t.Errorf("AuditBucket() = %v (HTTP %d) in %q, want %v (HTTP %d) in eu-west-1",
+					audit.Exposure, audit.ListStatusCode, audit.Region, tt.want, tt.wantList)
+			}
+		})
+	}
+}
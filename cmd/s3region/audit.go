@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	s3region "github.com/rohilsurana/aws-bucket-region-go"
+)
+
+// runAudit implements "s3region audit [options] <input...>". It prints the
+// region and anonymous exposure of each bucket, one per line.
+func runAudit(args []string) int {
+	fs := flag.NewFlagSet(appName+" audit", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "HTTP request timeout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage:
+  %s audit [options] <s3-identifier...>
+
+Checks whether each bucket allows anonymous ListObjectsV2, and prints its
+region and exposure: public-list, public-read or private.
+
+Options:
+`, appName)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	cfg := mustLoadConfig()
+
+	if fs.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "Error: S3 bucket identifier required\n\n")
+		fs.Usage()
+		return 1
+	}
+
+	client := &http.Client{
+		Timeout: *timeout,
+	}
+	status := 0
+	for _, input := range fs.Args() {
+		audit, err := s3region.AuditBucket(context.Background(), input, cfg.options(client)...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s\t%s\t%s\n", input, audit.Region, audit.Exposure)
+	}
+	return status
+}
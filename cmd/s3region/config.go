@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	s3region "github.com/rohilsurana/aws-bucket-region-go"
+)
+
+const (
+	configEnv  = "S3REGION_CONFIG"  // Overrides the config file path
+	aliasesEnv = "S3REGION_ALIASES" // Comma-separated name=identifier aliases
+)
+
+// cliConfig is the content of the s3region config file. Each non-empty line
+// that is not a # comment holds one directive:
+//
+//	scheme <prefix> <template>
+//	alias <name> <identifier>
+//
+// A scheme directive maps identifiers starting with prefix to the identifier
+// built from template, in which {1}, {2}, ... are replaced by the path
+// segments after the prefix and {rest} by the segments after the highest
+// numbered one used. For example:
+//
+//	scheme datalake:// s3://acme-{1}-datalake/{rest}
+//
+// maps datalake://team/dataset/file to s3://acme-team-datalake/dataset/file.
+// An alias directive names a bucket identifier, as does each name=identifier
+// pair in S3REGION_ALIASES, which wins over the config file.
+type cliConfig struct {
+	schemes []schemeRule
+	aliases map[string]string
+}
+
+// schemeRule is a scheme directive from the config file.
+type schemeRule struct {
+	prefix   string
+	template string
+	segments int // Highest {N} placeholder used in template
+}
+
+// placeholderPattern matches the placeholders of a scheme template.
+var placeholderPattern = regexp.MustCompile(`\{([1-9][0-9]*|rest)\}`)
+
+// configPath returns the config file path and whether it was set explicitly.
+func configPath() (string, bool) {
+	if path := os.Getenv(configEnv); path != "" {
+		return path, true
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(dir, appName, "config"), false
+}
+
+// loadConfig reads the config file. A missing default config file is not an
+// error, but a missing file named by S3REGION_CONFIG is.
+func loadConfig() (*cliConfig, error) {
+	cfg := &cliConfig{aliases: make(map[string]string)}
+	if err := cfg.loadFile(); err != nil {
+		return nil, err
+	}
+
+	for _, pair := range strings.Split(os.Getenv(aliasesEnv), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, target, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s: %q is not a name=identifier pair", aliasesEnv, pair)
+		}
+		cfg.aliases[strings.TrimSpace(name)] = strings.TrimSpace(target)
+	}
+	return cfg, nil
+}
+
+// mustLoadConfig loads the config and registers its scheme rules. It exits
+// if the config cannot be loaded.
+func mustLoadConfig() *cliConfig {
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: loading config: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.register()
+	return cfg
+}
+
+// loadFile reads the directives of the config file into the config.
+func (c *cliConfig) loadFile() error {
+	path, explicit := configPath()
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if err := c.parseDirective(fields); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseDirective adds a single config directive to the config.
+func (c *cliConfig) parseDirective(fields []string) error {
+	switch fields[0] {
+	case "scheme":
+		if len(fields) != 3 {
+			return fmt.Errorf("scheme directive needs a prefix and a template")
+		}
+		rule := schemeRule{prefix: fields[1], template: fields[2]}
+		for _, m := range placeholderPattern.FindAllStringSubmatch(rule.template, -1) {
+			if n, err := strconv.Atoi(m[1]); err == nil && n > rule.segments {
+				rule.segments = n
+			}
+		}
+		c.schemes = append(c.schemes, rule)
+		return nil
+	case "alias":
+		if len(fields) != 3 {
+			return fmt.Errorf("alias directive needs a name and an identifier")
+		}
+		c.aliases[fields[1]] = fields[2]
+		return nil
+	}
+	return fmt.Errorf("unknown directive %q", fields[0])
+}
+
+// register registers the scheme rules with the s3region parser registry.
+func (c *cliConfig) register() {
+	for _, rule := range c.schemes {
+		s3region.RegisterScheme(rule.prefix, rule.parse)
+	}
+}
+
+// options returns the lookup options for the config and HTTP client.
+func (c *cliConfig) options(client s3region.HTTPClient) []s3region.Option {
+	return []s3region.Option{
+		s3region.WithHTTPClient(client),
+		s3region.WithAliases(c.aliases),
+	}
+}
+
+// parse maps an identifier in the rule's scheme to an S3 identifier.
+func (r schemeRule) parse(input string) (*s3region.Reference, error) {
+	segments := strings.Split(input[len(r.prefix):], "/")
+	if len(segments) < r.segments || (r.segments > 0 && segments[r.segments-1] == "") {
+		return nil, fmt.Errorf("%s expects at least %d path segment(s)", r.prefix, r.segments)
+	}
+
+	mapped := placeholderPattern.ReplaceAllStringFunc(r.template, func(p string) string {
+		name := p[1 : len(p)-1]
+		if name == "rest" {
+			return strings.Join(segments[r.segments:], "/")
+		}
+		n, _ := strconv.Atoi(name)
+		return segments[n-1]
+	})
+	return s3region.Parse(strings.TrimSuffix(mapped, "/"))
+}
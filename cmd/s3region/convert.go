@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	s3region "github.com/rohilsurana/aws-bucket-region-go"
+)
+
+// convertTargets maps the short target names accepted by -to to styles.
+// Every s3region.Style name (e.g. "dualstack") is accepted as well.
+var convertTargets = map[string]s3region.Style{
+	"arn":      s3region.StyleARN,
+	"uri":      s3region.StyleS3URI,
+	"url":      s3region.StyleVirtualHosted,
+	"path-url": s3region.StylePathStyle,
+	"console":  s3region.StyleConsole,
+}
+
+// runConvert implements "s3region convert -to <format> [input...]". It
+// converts each input, or each line of stdin if none are given, to the target
+// format, looking up the bucket region when the format needs one.
+func runConvert(args []string) int {
+	fs := flag.NewFlagSet(appName+" convert", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "HTTP request timeout")
+	to := fs.String("to", "", "Target format: arn, uri, url, path-url, console, dualstack, fips, accelerate or website")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage:
+  %s convert -to <format> [input...]
+
+Converts S3 identifiers between formats. Reads one identifier per line from
+standard input if no input is given. The bucket region is looked up when the
+target format needs one.
+
+Options:
+`, appName)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	cfg := mustLoadConfig()
+
+	style, ok := convertTargets[*to]
+	if !ok {
+		for _, s := range s3region.Styles {
+			if s.String() == *to {
+				style, ok = s, true
+			}
+		}
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown target format %q\n\n", *to)
+		fs.Usage()
+		return 1
+	}
+
+	client := &http.Client{
+		Timeout: *timeout,
+	}
+	convert := func(input string) bool {
+		out, err := convertInput(context.Background(), input, style, cfg.options(client))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return false
+		}
+		fmt.Println(out)
+		return true
+	}
+
+	status := 0
+	if fs.NArg() > 0 {
+		for _, input := range fs.Args() {
+			if !convert(input) {
+				status = 1
+			}
+		}
+		return status
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" {
+			continue
+		}
+		if !convert(input) {
+			status = 1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		status = 1
+	}
+	return status
+}
+
+// convertInput parses input and formats it in the given style, looking up
+// the region first unless the style carries none.
+func convertInput(ctx context.Context, input string, style s3region.Style, opts []s3region.Option) (string, error) {
+	ref, err := s3region.Parse(input, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	switch style {
+	case s3region.StyleS3URI, s3region.StyleARN, s3region.StyleAccelerate:
+	default:
+		region, err := s3region.GetBucketRegion(ctx, input, opts...)
+		if err != nil {
+			return "", err
+		}
+		ref.Region = region
+	}
+	return s3region.Format(ref, style)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// writeUnifiedDiff writes a unified diff between oldText and newText. Both
+// texts must have the same number of lines, which holds for rewrites that
+// replace text within lines, so lines are compared one to one.
+func writeUnifiedDiff(w io.Writer, name, oldText, newText string) {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	var changed []int
+	for i := range oldLines {
+		if oldLines[i] != newLines[i] {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "--- a/%s\n+++ b/%s\n", name, name)
+	for len(changed) > 0 {
+		// Extend the hunk while the next change is within reach of its context
+		end := 1
+		for end < len(changed) && changed[end]-changed[end-1] <= 2*diffContext {
+			end++
+		}
+		first := max(changed[0]-diffContext, 0)
+		last := min(changed[end-1]+diffContext, len(oldLines)-1)
+
+		count := last - first + 1
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", first+1, count, first+1, count)
+		for i := first; i <= last; i++ {
+			if oldLines[i] == newLines[i] {
+				writeDiffLine(w, " ", oldLines[i])
+				continue
+			}
+			writeDiffLine(w, "-", oldLines[i])
+			writeDiffLine(w, "+", newLines[i])
+		}
+		changed = changed[end:]
+	}
+}
+
+// splitLines splits text into lines, keeping the line terminators so that a
+// missing newline at the end of the text can be reported.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeDiffLine writes a single diff line, marking a missing final newline.
+func writeDiffLine(w io.Writer, prefix, line string) {
+	if strings.HasSuffix(line, "\n") {
+		fmt.Fprint(w, prefix+line)
+		return
+	}
+	fmt.Fprintf(w, "%s%s\n\\ No newline at end of file\n", prefix, line)
+}
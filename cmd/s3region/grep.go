@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	s3region "github.com/rohilsurana/aws-bucket-region-go"
+)
+
+// runGrep implements "s3region grep [options] [file...]". It prints every S3
+// reference found in the files, or stdin if none are given, with its region.
+// The exit code follows grep: 0 if references were found, 1 if none, 2 on error.
+func runGrep(args []string) int {
+	fs := flag.NewFlagSet(appName+" grep", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "HTTP request timeout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage:
+  %s grep [options] [file...]
+
+Prints every S3 reference (URIs, ARNs, S3 and console URLs) found in the
+files, or standard input, as "file:line:column: reference region".
+
+Options:
+`, appName)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	cfg := mustLoadConfig()
+
+	client := &http.Client{
+		Timeout: *timeout,
+	}
+
+	// Regions are cached per bucket, since logs tend to repeat the same buckets
+	regions := make(map[string]string)
+	resolve := func(m s3region.Match) string {
+		ref := m.Reference
+		if region, ok := regions[ref.Bucket]; ok && ref.Region == "" {
+			return region
+		}
+		region, err := s3region.GetBucketRegion(context.Background(), m.Text, cfg.options(client)...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			region = "-"
+		}
+		if ref.Region == "" {
+			regions[ref.Bucket] = region
+		}
+		return region
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	found, failed := false, false
+	for _, file := range files {
+		name, data, err := readInput(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+			continue
+		}
+		for _, m := range s3region.ExtractIdentifiers(string(data)) {
+			found = true
+			fmt.Printf("%s:%d:%d: %s %s\n", name, m.Line, m.Column, m.Text, resolve(m))
+		}
+	}
+
+	switch {
+	case failed:
+		return 2
+	case !found:
+		return 1
+	}
+	return 0
+}
+
+// readInput reads a whole file, or stdin if file is "-". It returns the name
+// to print for the input.
+func readInput(file string) (string, []byte, error) {
+	if file == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return "(standard input)", data, err
+	}
+	data, err := os.ReadFile(file)
+	return file, data, err
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net/http"
@@ -23,6 +24,19 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "grep":
+			os.Exit(runGrep(os.Args[2:]))
+		case "rewrite":
+			os.Exit(runRewrite(os.Args[2:]))
+		case "convert":
+			os.Exit(runConvert(os.Args[2:]))
+		case "audit":
+			os.Exit(runAudit(os.Args[2:]))
+		}
+	}
+
 	flag.Parse()
 
 	if *help {
@@ -42,6 +56,7 @@ func main() {
 	}
 
 	input := flag.Arg(0)
+	cfg := mustLoadConfig()
 
 	// Create custom HTTP client with timeout
 	client := &http.Client{
@@ -52,10 +67,14 @@ func main() {
 	region, err := s3region.GetBucketRegion(
 		context.Background(),
 		input,
-		s3region.WithHTTPClient(client),
+		cfg.options(client)...,
 	)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var e *s3region.Error
+		if errors.As(err, &e) && e.RequestID != "" {
+			fmt.Fprintf(os.Stderr, "Request ID: %s\nHost ID: %s\n", e.RequestID, e.HostID)
+		}
 		os.Exit(1)
 	}
 
@@ -67,6 +86,16 @@ func printHelp() {
 
 Usage:
   %s [options] <s3-identifier>
+  %s grep [options] [file...]
+  %s rewrite [options] [file...]
+  %s convert -to <format> [input...]
+  %s audit [options] <s3-identifier...>
+
+Commands:
+  grep               Find S3 references in files or stdin and print their regions
+  rewrite            Rewrite global and path-style S3 URLs to regional virtual-hosted URLs
+  convert            Convert S3 identifiers between formats (arn, uri, url, path-url, console)
+  audit              Check whether buckets allow anonymous listing (public-list, public-read, private)
 
 Arguments:
   <s3-identifier>    S3 bucket identifier in any supported format:
@@ -80,12 +109,24 @@ Options:
   -version          Print version information
   -help             Show this help message
 
+Configuration:
+  Custom identifier schemes and bucket aliases are read from $S3REGION_CONFIG,
+  or s3region/config in the user config directory, one directive per line:
+    scheme datalake:// s3://acme-{1}-datalake/{rest}
+    alias prod-logs acme-prod-logs-7f3a2c-us-east-1
+  Aliases can also be set as name=identifier pairs in $S3REGION_ALIASES:
+    S3REGION_ALIASES=prod-logs=acme-prod-logs-7f3a2c-us-east-1,lake=s3://acme-lake
+
 Examples:
   %s my-bucket
   %s s3://my-bucket/path/to/object
   %s arn:aws:s3:::my-bucket
   %s https://my-bucket.s3.amazonaws.com/object
   %s -timeout 5s my-bucket
+  %s grep app.log config.yaml
+  %s rewrite -w config.yaml
+  %s convert -to arn s3://my-bucket/key
+  %s audit my-bucket s3://other-bucket
 
-`, appName, appName, appName, appName, appName, appName, appName)
+`, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName)
 }
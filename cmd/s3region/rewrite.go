@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	s3region "github.com/rohilsurana/aws-bucket-region-go"
+)
+
+// runRewrite implements "s3region rewrite [options] [file...]". It rewrites
+// global and path-style S3 URLs in the files, or stdin if none are given, to
+// the regional virtual-hosted form and prints a unified diff, or writes the
+// files in place with -w.
+func runRewrite(args []string) int {
+	fs := flag.NewFlagSet(appName+" rewrite", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "HTTP request timeout")
+	write := fs.Bool("w", false, "Write the result to the files instead of printing a diff")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage:
+  %s rewrite [options] [file...]
+
+Rewrites global (s3.amazonaws.com), path-style and legacy dash-style S3 URLs
+in the files, or standard input, to the regional virtual-hosted form
+https://<bucket>.s3.<region>.amazonaws.com/<key>. Presigned URLs are left
+alone. Prints a unified diff unless -w is given.
+
+Options:
+`, appName)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	cfg := mustLoadConfig()
+
+	client := &http.Client{
+		Timeout: *timeout,
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		if *write {
+			fmt.Fprintf(os.Stderr, "Error: -w requires file arguments\n")
+			return 1
+		}
+		files = []string{"-"}
+	}
+
+	status := 0
+	for _, file := range files {
+		name, data, err := readInput(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			status = 1
+			continue
+		}
+
+		text := string(data)
+		rewritten, rewrites, err := s3region.RewriteURLs(context.Background(), text, cfg.options(client)...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			status = 1
+		}
+		if len(rewrites) == 0 {
+			continue
+		}
+
+		if !*write {
+			writeUnifiedDiff(os.Stdout, name, text, rewritten)
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err == nil {
+			err = os.WriteFile(file, []byte(rewritten), info.Mode().Perm())
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: rewrote %d URL(s)\n", name, len(rewrites))
+	}
+	return status
+}
@@ -0,0 +1,79 @@
+package s3region
+
+import (
+	"net/url"
+	"strings"
+)
+
+// consolePartition returns the partition of an AWS console host, such as
+// console.aws.amazon.com, s3.console.aws.amazon.com or
+// us-west-2.console.aws.amazon.com. It reports false for any other host.
+func consolePartition(host string) (string, bool) {
+	host = strings.ToLower(host)
+	for _, p := range partitions {
+		if host == p.consoleDomain || strings.HasSuffix(host, "."+p.consoleDomain) {
+			return p.id, true
+		}
+	}
+	return "", false
+}
+
+// isConsoleHost reports whether host is an AWS console host.
+func isConsoleHost(host string) bool {
+	_, ok := consolePartition(host)
+	return ok
+}
+
+// parseConsoleURL parses an S3 console link such as
+// https://s3.console.aws.amazon.com/s3/buckets/my-bucket?region=us-west-2&prefix=logs/
+// or https://s3.console.aws.amazon.com/s3/object/my-bucket?prefix=key.
+// It reports false if rawURL is not a console URL. The bucket name is not validated.
+func parseConsoleURL(rawURL string) (*Reference, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, false
+	}
+	partition, ok := consolePartition(u.Hostname())
+	if !ok {
+		return nil, false
+	}
+
+	ref := &Reference{Input: rawURL, Scheme: strings.ToLower(u.Scheme), Partition: partition}
+	path := strings.TrimPrefix(u.Path, "/")
+	for _, prefix := range []string{"s3/buckets/", "s3/object/"} {
+		if strings.HasPrefix(path, prefix) {
+			ref.Bucket, _ = splitBucketPath(strings.TrimPrefix(path, prefix))
+			break
+		}
+	}
+
+	query := u.Query()
+	ref.Key = query.Get("prefix")
+
+	// The region query parameter wins over a regional console host
+	// such as us-west-2.console.aws.amazon.com.
+	ref.RegionHint = query.Get("region")
+	if ref.RegionHint == "" {
+		if label, _, _ := strings.Cut(u.Hostname(), "."); isRegionLike(label) {
+			ref.RegionHint = label
+		}
+	}
+	return ref, true
+}
+
+// ParseConsoleURL parses an AWS console bucket or object link into a Reference
+// without making any network request. Links from the China and GovCloud
+// consoles are accepted as well. The region query parameter is returned as
+// a hint in Reference.RegionHint.
+func ParseConsoleURL(url string) (*Reference, error) {
+	const op = "ParseConsoleURL"
+
+	ref, ok := parseConsoleURL(url)
+	if !ok {
+		return nil, newError(op, "", url, ErrNotConsoleURL)
+	}
+	if !isValidBucketName(ref.Bucket) {
+		return nil, newError(op, ref.Bucket, url, ErrInvalidBucketName)
+	}
+	return ref, nil
+}
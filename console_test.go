@@ -0,0 +1,115 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseConsoleURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantBucket string
+		wantKey    string
+		wantHint   string
+	}{
+		{
+			name:       "bucket with region and prefix",
+			input:      "https://s3.console.aws.amazon.com/s3/buckets/my-bucket?region=us-west-2&prefix=logs/&showversions=false",
+			wantBucket: "my-bucket",
+			wantKey:    "logs/",
+			wantHint:   "us-west-2",
+		},
+		{
+			name:       "object with escaped key",
+			input:      "https://s3.console.aws.amazon.com/s3/object/my-bucket?region=eu-west-1&prefix=path%2Fto%2Ffile.txt",
+			wantBucket: "my-bucket",
+			wantKey:    "path/to/file.txt",
+			wantHint:   "eu-west-1",
+		},
+		{
+			name:       "regional console host",
+			input:      "https://ap-south-1.console.aws.amazon.com/s3/buckets/my-bucket",
+			wantBucket: "my-bucket",
+			wantHint:   "ap-south-1",
+		},
+		{
+			name:       "without region",
+			input:      "https://s3.console.aws.amazon.com/s3/object/my-bucket?prefix=key",
+			wantBucket: "my-bucket",
+			wantKey:    "key",
+		},
+		{
+			name:       "china console",
+			input:      "https://console.amazonaws.cn/s3/buckets/my-bucket?region=cn-north-1",
+			wantBucket: "my-bucket",
+			wantHint:   "cn-north-1",
+		},
+		{
+			name:       "govcloud console",
+			input:      "https://console.amazonaws-us-gov.com/s3/buckets/my-bucket?region=us-gov-west-1&tab=objects",
+			wantBucket: "my-bucket",
+			wantHint:   "us-gov-west-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseConsoleURL(tt.input)
+			if err != nil {
+				t.Fatalf("ParseConsoleURL(%q) error = %v", tt.input, err)
+			}
+			if ref.Bucket != tt.wantBucket {
+				t.Errorf("Bucket = %q, want %q", ref.Bucket, tt.wantBucket)
+			}
+			if ref.Key != tt.wantKey {
+				t.Errorf("Key = %q, want %q", ref.Key, tt.wantKey)
+			}
+			if ref.RegionHint != tt.wantHint {
+				t.Errorf("RegionHint = %q, want %q", ref.RegionHint, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestParseConsoleURLInvalid(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr error
+	}{
+		{"https://my-bucket.s3.amazonaws.com/key", ErrNotConsoleURL},
+		{"https://s3.console.aws.amazon.com/s3/home?region=us-east-1", ErrInvalidBucketName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseConsoleURL(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseConsoleURL(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetBucketRegionConsoleURL(t *testing.T) {
+	input := "https://s3.console.aws.amazon.com/s3/buckets/my-bucket?region=us-west-2"
+
+	client := &mockHTTPClient{region: "eu-west-1"}
+	region, err := GetBucketRegion(context.Background(), input, WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("GetBucketRegion() error = %v", err)
+	}
+	if region != "eu-west-1" || !client.called {
+		t.Errorf("GetBucketRegion() = %q, called = %v, want verified region %q", region, client.called, "eu-west-1")
+	}
+
+	client = &mockHTTPClient{region: "eu-west-1"}
+	region, err = GetBucketRegion(context.Background(), input, WithHTTPClient(client), WithRegionHints(true))
+	if err != nil {
+		t.Fatalf("GetBucketRegion() error = %v", err)
+	}
+	if region != "us-west-2" || client.called {
+		t.Errorf("GetBucketRegion() = %q, called = %v, want hint %q offline", region, client.called, "us-west-2")
+	}
+}
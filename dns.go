@@ -0,0 +1,134 @@
+package s3region
+
+import (
+	"context"
+	"fmt"
+	neturl "net/url"
+	"strings"
+)
+
+// maxCNAMEDepth bounds how many CNAME records are followed for one host.
+const maxCNAMEDepth = 8
+
+// dnsRegionHosts maps S3 hostnames that carry no region label to the region
+// they serve. Most regional hosts, such as s3-w.us-west-2.amazonaws.com, are
+// parsed instead.
+var dnsRegionHosts = map[string]string{
+	"s3-1.amazonaws.com":          "us-east-1",
+	"s3-1-w.amazonaws.com":        "us-east-1",
+	"s3-external-1.amazonaws.com": "us-east-1",
+	"s3-external-2.amazonaws.com": "us-east-1",
+}
+
+// dnsNameRegion returns the region served by an S3 hostname found in DNS
+// answers, or "" if the name does not identify a region.
+func dnsNameRegion(name string) string {
+	if region, ok := dnsRegionHosts[name]; ok {
+		return region
+	}
+	// Legacy names such as s3-us-west-2-w.amazonaws.com carry a -w or -r-w
+	// suffix on the endpoint label
+	if label, rest, ok := strings.Cut(name, "."); ok && strings.HasPrefix(label, "s3") {
+		for _, suffix := range []string{"-r-w", "-w"} {
+			if trimmed, ok := strings.CutSuffix(label, suffix); ok {
+				name = trimmed + "." + rest
+				break
+			}
+		}
+	}
+	return endpointRegion(name)
+}
+
+// dnsRegion infers a bucket's region purely from DNS: the global endpoint
+// <bucket>.s3.amazonaws.com is a CNAME to a host in the bucket's region,
+// such as s3-w.us-west-2.amazonaws.com. S3 answers for nonexistent buckets
+// too, so a DNS answer does not prove the bucket exists.
+func dnsRegion(ctx context.Context, cfg *config, bucketName string) (string, error) {
+	name := bucketName + ".s3.amazonaws.com"
+	for i := 0; i < maxCNAMEDepth; i++ {
+		cname, err := cfg.resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve CNAME for %s: %w", name, err)
+		}
+		cname = strings.TrimSuffix(strings.ToLower(cname), ".")
+		if cname == "" || cname == name {
+			break
+		}
+		if region := dnsNameRegion(cname); region != "" {
+			return region, nil
+		}
+		name = cname
+	}
+	return "", ErrDNSRegionUnknown
+}
+
+// followCNAME follows the CNAME chain of host until it reaches an S3 endpoint
+// hostname. It returns the S3 hostname and its parsed form, or ErrCNAMENotS3
+// if the chain ends elsewhere.
+func followCNAME(ctx context.Context, resolver Resolver, host string) (string, s3Host, error) {
+	name := host
+	for i := 0; i < maxCNAMEDepth; i++ {
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return "", s3Host{}, fmt.Errorf("failed to resolve CNAME for %s: %w", name, err)
+		}
+		cname = strings.TrimSuffix(strings.ToLower(cname), ".")
+		if h, ok := parseS3Host(cname); ok {
+			return cname, h, nil
+		}
+		if cname == "" || cname == name {
+			break
+		}
+		name = cname
+	}
+	return "", s3Host{}, ErrCNAMENotS3
+}
+
+// resolveCustomDomain rewrites a reference whose host is a custom domain,
+// such as assets.example.com, into the S3 bucket its CNAME chain points to.
+// References whose host is already an S3 endpoint are left untouched.
+func resolveCustomDomain(ctx context.Context, ref *Reference, cfg *config) error {
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return nil
+	}
+	host, path := splitURLHost(ref.Input)
+	if _, ok := parseS3Host(host); ok || isConsoleHost(host) {
+		return nil
+	}
+
+	_, h, err := followCNAME(ctx, cfg.resolver, strings.ToLower(host))
+	if err != nil {
+		return err
+	}
+
+	// A CNAME to a bucket endpoint names the bucket; a CNAME to a bare
+	// endpoint means S3 takes the bucket name from the Host header.
+	ref.Bucket = h.bucket
+	if ref.Bucket == "" {
+		ref.Bucket = strings.ToLower(host)
+	}
+	ref.Key = path
+	if key, err := neturl.PathUnescape(path); err == nil {
+		ref.Key = key
+	}
+	ref.Region = h.region
+	ref.RegionHint = h.regionHint
+	return nil
+}
+
+// splitURLHost returns the host (without port) and path of an HTTP/HTTPS URL,
+// without the query string and fragment.
+func splitURLHost(rawURL string) (host, path string) {
+	_, url, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		url = rawURL
+	}
+	if idx := strings.IndexAny(url, "?#"); idx != -1 {
+		url = url[:idx]
+	}
+	host, path, _ = strings.Cut(url, "/")
+	if idx := strings.LastIndex(host, ":"); idx != -1 {
+		host = host[:idx]
+	}
+	return host, path
+}
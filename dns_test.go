@@ -0,0 +1,239 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// fakeResolver is a Resolver answering CNAME lookups from a map. Hosts
+// without an entry resolve to themselves, like hosts without a CNAME record.
+type fakeResolver struct {
+	cnames map[string]string
+	err    error
+}
+
+func (f *fakeResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	if cname, ok := f.cnames[host]; ok {
+		return cname, nil
+	}
+	return host + ".", nil
+}
+
+func TestGetBucketRegionFromHTTPURLCNAME(t *testing.T) {
+	resolver := &fakeResolver{cnames: map[string]string{
+		"assets.example.com":  "assets.example.com.s3.amazonaws.com.",
+		"cdn.example.com":     "edge.example.net.",
+		"edge.example.net":    "media.example.com.s3-website-eu-west-1.amazonaws.com.",
+		"private.example.com": "bucket.vpce-0a1b2c-3d4e.s3.us-east-1.vpce.amazonaws.com.",
+		"www.example.com":     "www.example.org.",
+	}}
+
+	tests := []struct {
+		name       string
+		input      string
+		wantRegion string
+		wantErr    error
+	}{
+		{
+			name:       "cname to virtual-hosted endpoint",
+			input:      "https://assets.example.com/img/logo.png",
+			wantRegion: "us-west-1",
+		},
+		{
+			name:       "cname chain to website endpoint",
+			input:      "https://cdn.example.com/index.html",
+			wantRegion: "us-west-1",
+		},
+		{
+			name:       "cname to privatelink endpoint",
+			input:      "https://private.example.com/key",
+			wantRegion: "us-east-1",
+		},
+		{
+			name:    "cname chain without s3 endpoint",
+			input:   "https://www.example.com/",
+			wantErr: ErrCNAMENotS3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockHTTPClient{region: "us-west-1"}
+			region, err := GetBucketRegion(context.Background(), tt.input,
+				WithHTTPClient(client), WithResolver(resolver), WithCNAMELookup(true))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetBucketRegion() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBucketRegion() error = %v", err)
+			}
+			if region != tt.wantRegion {
+				t.Errorf("GetBucketRegion() = %q, want %q", region, tt.wantRegion)
+			}
+		})
+	}
+}
+
+func TestResolveCustomDomainBucket(t *testing.T) {
+	resolver := &fakeResolver{cnames: map[string]string{
+		"assets.example.com": "assets.example.com.s3.amazonaws.com.",
+		"files.example.com":  "s3.us-west-2.amazonaws.com.",
+	}}
+	cfg := newConfig([]Option{WithResolver(resolver)})
+
+	tests := []struct {
+		input      string
+		wantBucket string
+		wantKey    string
+		wantHint   string
+	}{
+		{"https://assets.example.com/img/logo.png", "assets.example.com", "img/logo.png", ""},
+		{"https://files.example.com/report.csv", "files.example.com", "report.csv", "us-west-2"},
+		{"https://assets.example.com/a%20b", "assets.example.com", "a b", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ref := parseHTTPURL(tt.input)
+			if err := resolveCustomDomain(context.Background(), ref, cfg); err != nil {
+				t.Fatalf("resolveCustomDomain() error = %v", err)
+			}
+			if ref.Bucket != tt.wantBucket || ref.Key != tt.wantKey || ref.RegionHint != tt.wantHint {
+				t.Errorf("resolveCustomDomain() = %q, %q, %q, want %q, %q, %q",
+					ref.Bucket, ref.Key, ref.RegionHint, tt.wantBucket, tt.wantKey, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestGetBucketRegionCNAMEResolverError(t *testing.T) {
+	resolver := &fakeResolver{err: fmt.Errorf("no such host")}
+
+	_, err := GetBucketRegion(context.Background(), "https://assets.example.com/key",
+		WithResolver(resolver), WithCNAMELookup(true))
+	var e *Error
+	if !errors.As(err, &e) || e.Op != "GetBucketRegionFromHTTPURL" {
+		t.Fatalf("GetBucketRegion() error = %v, want GetBucketRegionFromHTTPURL error", err)
+	}
+}
+
+// failingHTTPClient is an HTTPClient whose requests always fail, with err
+// if set.
+type failingHTTPClient struct {
+	err    error
+	called bool
+}
+
+func (f *failingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	f.called = true
+	if f.err != nil {
+		return nil, f.err
+	}
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestGetBucketRegionByNameDNSStrategy(t *testing.T) {
+	resolver := &fakeResolver{cnames: map[string]string{
+		"west-bucket.s3.amazonaws.com":   "s3-w.us-west-2.amazonaws.com.",
+		"east-bucket.s3.amazonaws.com":   "s3-1-w.amazonaws.com.",
+		"eu-bucket.s3.amazonaws.com":     "s3-r-w.eu-central-1.amazonaws.com.",
+		"legacy-bucket.s3.amazonaws.com": "s3-us-west-2-w.amazonaws.com.",
+		"dublin-bucket.s3.amazonaws.com": "s3-eu-west-1-w.amazonaws.com.",
+		"paris-bucket.s3.amazonaws.com":  "s3-eu-west-3-r-w.amazonaws.com.",
+	}}
+
+	tests := []struct {
+		name       string
+		bucket     string
+		strategy   Strategy
+		client     HTTPClient
+		wantRegion string
+		wantErr    error
+	}{
+		{
+			name:       "regional host",
+			bucket:     "west-bucket",
+			strategy:   StrategyDNS,
+			wantRegion: "us-west-2",
+		},
+		{
+			name:       "known host table",
+			bucket:     "east-bucket",
+			strategy:   StrategyDNS,
+			wantRegion: "us-east-1",
+		},
+		{
+			name:       "legacy dash host",
+			bucket:     "legacy-bucket",
+			strategy:   StrategyDNS,
+			wantRegion: "us-west-2",
+		},
+		{
+			name:       "legacy dash host in eu-west-1",
+			bucket:     "dublin-bucket",
+			strategy:   StrategyDNS,
+			wantRegion: "eu-west-1",
+		},
+		{
+			name:       "legacy dash host with -r-w",
+			bucket:     "paris-bucket",
+			strategy:   StrategyDNS,
+			wantRegion: "eu-west-3",
+		},
+		{
+			name:     "no region in answers",
+			bucket:   "unknown-bucket",
+			strategy: StrategyDNS,
+			wantErr:  ErrDNSRegionUnknown,
+		},
+		{
+			name:       "dns falls back to http",
+			bucket:     "unknown-bucket",
+			strategy:   StrategyDNSThenHTTP,
+			client:     &mockHTTPClient{region: "sa-east-1"},
+			wantRegion: "sa-east-1",
+		},
+		{
+			name:       "http falls back to dns",
+			bucket:     "eu-bucket",
+			strategy:   StrategyHTTPThenDNS,
+			client:     &failingHTTPClient{},
+			wantRegion: "eu-central-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.client
+			if client == nil {
+				client = &failingHTTPClient{}
+			}
+			region, err := GetBucketRegionByName(context.Background(), tt.bucket,
+				WithHTTPClient(client), WithResolver(resolver), WithStrategy(tt.strategy))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetBucketRegionByName() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetBucketRegionByName() error = %v", err)
+			}
+			if region != tt.wantRegion {
+				t.Errorf("GetBucketRegionByName() = %q, want %q", region, tt.wantRegion)
+			}
+			if f, ok := client.(*failingHTTPClient); ok && tt.strategy == StrategyDNS && f.called {
+				t.Error("HTTP client called with StrategyDNS")
+			}
+		})
+	}
+}
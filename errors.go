@@ -1,20 +1,57 @@
 package s3region
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
 )
 
 var ErrRegionHeaderNotFound = errors.New("x-amz-bucket-region header not found in response")
 var ErrBucketNotFound = errors.New("aws s3 bucket not found") // HEAD request returns 404
 var ErrInvalidBucketName = errors.New("invalid S3 bucket name")
+var ErrNotPresigned = errors.New("URL is not presigned")
+var ErrMalformedPresignedURL = errors.New("malformed presigned URL")
+var ErrNotConsoleURL = errors.New("not an AWS console S3 URL")
+var ErrCNAMENotS3 = errors.New("CNAME chain does not lead to an S3 endpoint")
+var ErrDNSRegionUnknown = errors.New("region could not be inferred from DNS")
+var ErrUnsupportedStyle = errors.New("style not supported for this region or partition")
+var ErrRedirectNotAWS = errors.New("redirect to a host that is not an S3 endpoint")
+var ErrAccessDenied = errors.New("access denied")              // 403 response without a region
+var ErrUnexpectedStatus = errors.New("unexpected HTTP status") // Error or unknown status without a region
+var ErrAmbiguousInput = errors.New("ambiguous S3 identifier")  // Rejected in ParseStrict mode
+var ErrThrottled = errors.New("request throttled")             // 429 or 503 response, such as S3 SlowDown
+var ErrTimeout = errors.New("request timed out")
+var ErrTLS = errors.New("TLS handshake or certificate verification failed")
+var ErrNetwork = errors.New("network error") // Connection failures other than timeouts and TLS errors
+var ErrMalformedResponse = errors.New("malformed S3 response")
+var ErrNotObject = errors.New("input does not name an object")
+var ErrObjectNotFound = errors.New("aws s3 object not found") // HEAD request on the object returns 404
+var ErrNoBucket = errors.New("input names an access point, not a bucket")
 
 // Error provides structured error information with context about the operation.
 type Error struct {
 	Op         string // Operation: "GetBucketRegion", "GetBucketRegionByName", etc.
 	BucketName string // The bucket name being queried
 	Input      string // Original input provided by user
-	Err        error  // Underlying error
+	StatusCode int    // HTTP status of the S3 response, 0 if none was received
+	Code       string // S3 error code, such as "AccessDenied", if the response had an error body
+	Message    string // S3 error message, if the response had an error body
+	Attempts   int    // Attempts made for the failed request, see WithRetry
+
+	// Metadata of the failed request, empty if it failed before any request
+	Endpoint  string        // URL of the request
+	RequestID string        // x-amz-request-id header, to quote to AWS Support
+	HostID    string        // x-amz-id-2 header, to quote to AWS Support
+	Elapsed   time.Duration // Time the whole lookup took
+
+	Err error // Underlying error
 }
 
 func (e *Error) Error() string {
@@ -25,12 +62,133 @@ func (e *Error) Unwrap() error {
 	return e.Err
 }
 
-// newError creates a new Error with the given parameters.
+// newError creates a new Error with the given parameters. The metadata of
+// a failed request is taken from err, or from an Error it wraps.
 func newError(op, bucketName, input string, err error) error {
-	return &Error{
+	e := &Error{
 		Op:         op,
 		BucketName: bucketName,
 		Input:      input,
 		Err:        err,
 	}
+	var inner *Error
+	var re *requestError
+	switch {
+	case errors.As(err, &inner):
+		e.StatusCode, e.Code, e.Message, e.Attempts = inner.StatusCode, inner.Code, inner.Message, inner.Attempts
+		e.Endpoint, e.RequestID, e.HostID, e.Elapsed = inner.Endpoint, inner.RequestID, inner.HostID, inner.Elapsed
+	case errors.As(err, &re):
+		e.StatusCode, e.Code, e.Message, e.Attempts = re.status, re.code, re.message, re.attempts
+		e.Endpoint, e.RequestID, e.HostID = re.endpoint, re.requestID, re.hostID
+	}
+	return e
+}
+
+// requestError records the metadata, S3 error and number of attempts of a
+// failed request. The status is 0 if no response was received.
+type requestError struct {
+	endpoint  string
+	status    int
+	requestID string
+	hostID    string
+	code      string
+	message   string
+	attempts  int
+	err       error
+}
+
+func (e *requestError) Error() string {
+	switch {
+	case e.status == 0:
+		return e.err.Error()
+	case e.code != "":
+		return fmt.Sprintf("%v (HTTP %d %s)", e.err, e.status, e.code)
+	}
+	return fmt.Sprintf("%v (HTTP %d)", e.err, e.status)
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// IsNotFound reports whether err means the bucket or object does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrBucketNotFound) || errors.Is(err, ErrObjectNotFound)
+}
+
+// IsRetryable reports whether sending the same request again may succeed:
+// throttling, timeouts, connection resets and 5xx responses. Errors caused
+// by a canceled or expired context are not retryable; request timeouts, such
+// as that of http.Client.Timeout, are.
+func IsRetryable(err error) bool {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, ErrThrottled), errors.Is(err, ErrTimeout):
+		return true
+	case errors.Is(err, context.DeadlineExceeded):
+		// Not wrapped in ErrTimeout, so the caller's context expired
+		return false
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+	var re *requestError
+	return errors.As(err, &re) && retryableStatus(re.status)
+}
+
+// IsTemporary reports whether err is caused by a condition that may clear
+// later, such as a network failure, a timeout or throttling, rather than by
+// the input or the bucket. Every retryable error is temporary.
+func IsTemporary(err error) bool {
+	return IsRetryable(err) || errors.Is(err, ErrNetwork) || errors.Is(err, ErrTimeout)
+}
+
+// classifyRequestError wraps an error returned by HTTPClient.Do or while
+// reading a response body with ErrTimeout, ErrTLS or ErrNetwork. Errors of
+// canceled requests are returned as is; sendOnce does the same for requests
+// whose context expired.
+func classifyRequestError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case isTLSError(err):
+		return fmt.Errorf("%w: %w", ErrTLS, err)
+	}
+	return fmt.Errorf("%w: %w", ErrNetwork, err)
+}
+
+// isTLSError reports whether err comes from the TLS handshake or from
+// verifying the server's certificate.
+func isTLSError(err error) bool {
+	var (
+		verifyErr  *tls.CertificateVerificationError
+		recordErr  tls.RecordHeaderError
+		alertErr   tls.AlertError
+		authErr    x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+	)
+	return errors.As(err, &verifyErr) || errors.As(err, &recordErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &authErr) || errors.As(err, &hostErr) || errors.As(err, &invalidErr)
+}
+
+// statusErrorClass returns the sentinel error for a response status that
+// does not reveal the bucket's region.
+func statusErrorClass(status int) error {
+	switch status {
+	case 403:
+		return ErrAccessDenied
+	case 404:
+		return ErrBucketNotFound
+	case 429, 503:
+		return ErrThrottled
+	}
+	return ErrUnexpectedStatus
 }
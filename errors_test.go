@@ -0,0 +1,136 @@
+package s3region
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// timeoutError is a net.Error reporting a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorTaxonomy(t *testing.T) {
+	tests := []struct {
+		name          string
+		client        HTTPClient
+		wantErr       error
+		wantRetryable bool
+		wantTemporary bool
+	}{
+		{
+			name:          "timeout",
+			client:        &failingHTTPClient{err: &net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}},
+			wantErr:       ErrTimeout,
+			wantRetryable: true,
+			wantTemporary: true,
+		},
+		{
+			name:          "connection reset",
+			client:        &failingHTTPClient{err: &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}},
+			wantErr:       ErrNetwork,
+			wantRetryable: true,
+			wantTemporary: true,
+		},
+		{
+			name:          "connection refused",
+			client:        &failingHTTPClient{err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}},
+			wantErr:       ErrNetwork,
+			wantTemporary: true,
+		},
+		{
+			name:    "unknown certificate authority",
+			client:  &failingHTTPClient{err: fmt.Errorf("tls: failed to verify certificate: %w", x509.UnknownAuthorityError{})},
+			wantErr: ErrTLS,
+		},
+		{
+			name:          "throttled",
+			client:        &routeClient{routes: map[string]stubResponse{headURL: {503, nil, ""}}},
+			wantErr:       ErrThrottled,
+			wantRetryable: true,
+			wantTemporary: true,
+		},
+		{
+			name:          "server error",
+			client:        &routeClient{routes: map[string]stubResponse{headURL: {500, nil, ""}}},
+			wantErr:       ErrUnexpectedStatus,
+			wantRetryable: true,
+			wantTemporary: true,
+		},
+		{
+			name:    "access denied",
+			client:  &routeClient{routes: map[string]stubResponse{headURL: {403, nil, ""}, locationURL: {403, nil, ""}}},
+			wantErr: ErrAccessDenied,
+		},
+		{
+			name: "malformed location constraint",
+			client: &routeClient{routes: map[string]stubResponse{
+				headURL:     {200, nil, ""},
+				locationURL: {200, nil, "<html>captive portal</html>"},
+			}},
+			wantErr: ErrMalformedResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetBucketRegion(context.Background(), "my-bucket", WithHTTPClient(tt.client))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetBucketRegion() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := IsRetryable(err); got != tt.wantRetryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRetryable)
+			}
+			if got := IsTemporary(err); got != tt.wantTemporary {
+				t.Errorf("IsTemporary() = %v, want %v", got, tt.wantTemporary)
+			}
+			if IsNotFound(err) {
+				t.Error("IsNotFound() = true, want false")
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	client := &routeClient{routes: map[string]stubResponse{headURL: {404, nil, ""}}}
+	_, err := GetBucketRegion(context.Background(), "s3://my-bucket/key", WithHTTPClient(client))
+	if !IsNotFound(err) || IsRetryable(err) || IsTemporary(err) {
+		t.Errorf("error = %v, want not found and not temporary", err)
+	}
+}
+
+func TestCanceledIsNotRetryable(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := GetBucketRegion(ctx, "my-bucket", WithHTTPClient(&failingHTTPClient{err: context.Canceled}))
+	if !errors.Is(err, context.Canceled) || IsRetryable(err) || IsTemporary(err) {
+		t.Errorf("error = %v, want canceled and not temporary", err)
+	}
+}
+
+func TestExpiredContextIsNotRetryable(t *testing.T) {
+	if IsRetryable(context.DeadlineExceeded) {
+		t.Error("IsRetryable(context.DeadlineExceeded) = true, want false")
+	}
+
+	// The request hangs until the caller's deadline passes
+	hang := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := GetBucketRegion(ctx, "my-bucket", WithHTTPClient(&http.Client{Transport: hang}))
+	if !errors.Is(err, context.DeadlineExceeded) || IsRetryable(err) || IsTemporary(err) {
+		t.Errorf("error = %v, want deadline exceeded and not temporary", err)
+	}
+}
@@ -0,0 +1,77 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// BucketState is the existence and anonymous access of a bucket, as
+// reported by BucketExists.
+type BucketState int
+
+const (
+	BucketUnknown  BucketState = iota // S3 gave no answer, see the error returned with it
+	BucketNotFound                    // The bucket does not exist (404)
+	BucketPrivate                     // The bucket exists and denies anonymous requests (403)
+	BucketPublic                      // The bucket exists and allows anonymous requests (200)
+)
+
+// String returns the name of the state, such as "not-found" or "private".
+func (s BucketState) String() string {
+	switch s {
+	case BucketNotFound:
+		return "not-found"
+	case BucketPrivate:
+		return "private"
+	case BucketPublic:
+		return "public"
+	}
+	return "unknown"
+}
+
+// BucketExists accepts any S3 identifier format accepted by GetBucketRegion
+// and reports whether the bucket exists and allows anonymous requests. The
+// error is non-nil only with BucketUnknown and gives the reason, such as an
+// invalid bucket name, a network failure or throttling.
+//
+// The answer comes from the region lookup. If it does not tell, such as
+// after a redirect, a DNS lookup or for a region carried by the input, a
+// HEAD request is sent to the bucket's regional endpoint.
+func BucketExists(ctx context.Context, input string, opts ...Option) (BucketState, error) {
+	const op = "BucketExists"
+
+	res, err := lookupInput(ctx, op, input, opts)
+	switch {
+	case IsNotFound(err):
+		return BucketNotFound, nil
+	case errors.Is(err, ErrAccessDenied):
+		return BucketPrivate, nil
+	case err != nil:
+		return BucketUnknown, err
+	}
+	switch res.Access {
+	case AccessPublic:
+		return BucketPublic, nil
+	case AccessPrivate:
+		return BucketPrivate, nil
+	}
+	if res.Bucket == "" {
+		return BucketUnknown, newError(op, "", input, ErrNoBucket)
+	}
+
+	cfg := newConfig(opts)
+	resp, err := send(ctx, cfg, http.MethodHead, endpointURL(res.Bucket, "", res.Region))
+	if err != nil {
+		return BucketUnknown, newError(op, res.Bucket, input, err)
+	}
+	switch resp.status {
+	case http.StatusOK:
+		return BucketPublic, nil
+	case http.StatusForbidden:
+		return BucketPrivate, nil
+	case http.StatusNotFound:
+		return BucketNotFound, nil
+	}
+	return BucketUnknown, newError(op, res.Bucket, input, resp.error(statusErrorClass(resp.status)))
+}
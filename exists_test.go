@@ -0,0 +1,111 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBucketExistsDottedName(t *testing.T) {
+	client := &routeClient{routes: map[string]stubResponse{
+		"HEAD https://my.bucket.s3.amazonaws.com":           {301, map[string]string{"x-amz-bucket-region": "eu-west-1"}, ""},
+		"HEAD https://s3.eu-west-1.amazonaws.com/my.bucket": {200, nil, ""},
+	}}
+	got, err := BucketExists(context.Background(), "my.bucket", WithHTTPClient(client))
+	if got != BucketPublic || err != nil {
+		t.Errorf("BucketExists() = %v, %v, want %v; requests = %q", got, err, BucketPublic, client.requests)
+	}
+}
+
+func TestBucketExists(t *testing.T) {
+	region := map[string]string{"x-amz-bucket-region": "eu-west-1"}
+	regionalURL := "HEAD https://my-bucket.s3.eu-west-1.amazonaws.com"
+
+	tests := []struct {
+		name      string
+		input     string
+		routes    map[string]stubResponse
+		want      BucketState
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "public",
+			input:     "s3://my-bucket/key",
+			routes:    map[string]stubResponse{headURL: {200, region, ""}},
+			want:      BucketPublic,
+			wantCalls: 1,
+		},
+		{
+			name:      "private",
+			input:     "arn:aws:s3:::my-bucket",
+			routes:    map[string]stubResponse{headURL: {403, region, ""}},
+			want:      BucketPrivate,
+			wantCalls: 1,
+		},
+		{
+			name:      "private without region",
+			input:     "my-bucket",
+			routes:    map[string]stubResponse{headURL: {403, nil, ""}, locationURL: {403, nil, ""}},
+			want:      BucketPrivate,
+			wantCalls: 2,
+		},
+		{
+			name:      "not found",
+			input:     "https://my-bucket.s3.amazonaws.com/key",
+			routes:    map[string]stubResponse{headURL: {404, nil, ""}},
+			want:      BucketNotFound,
+			wantCalls: 1,
+		},
+		{
+			name:      "redirect checked at regional endpoint",
+			input:     "my-bucket",
+			routes:    map[string]stubResponse{headURL: {301, region, ""}, regionalURL: {403, region, ""}},
+			want:      BucketPrivate,
+			wantCalls: 2,
+		},
+		{
+			name:      "pinned region checked at regional endpoint",
+			input:     "https://my-bucket.bucket.vpce-1a2b3c4d-5e6f.s3.eu-west-1.vpce.amazonaws.com/key",
+			routes:    map[string]stubResponse{regionalURL: {200, region, ""}},
+			want:      BucketPublic,
+			wantCalls: 1,
+		},
+		{
+			name:    "invalid name",
+			input:   "My_Bucket",
+			want:    BucketUnknown,
+			wantErr: ErrInvalidBucketName,
+		},
+		{
+			name:    "access point",
+			input:   "https://my-ap-123456789012.s3-accesspoint.us-west-2.amazonaws.com/key",
+			want:    BucketUnknown,
+			wantErr: ErrNoBucket,
+		},
+		{
+			name:      "throttled",
+			input:     "my-bucket",
+			routes:    map[string]stubResponse{headURL: {503, nil, ""}},
+			want:      BucketUnknown,
+			wantErr:   ErrThrottled,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &routeClient{routes: tt.routes}
+			got, err := BucketExists(context.Background(), tt.input, WithHTTPClient(client))
+			if got != tt.want {
+				t.Errorf("BucketExists() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr == nil && err != nil || !errors.Is(err, tt.wantErr) {
+				t.Errorf("BucketExists() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(client.requests) != tt.wantCalls {
+				t.Errorf("requests = %q, want %d", client.requests, tt.wantCalls)
+			}
+		})
+	}
+}
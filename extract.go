@@ -0,0 +1,76 @@
+package s3region
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Match is an S3 bucket reference found in free text by ExtractIdentifiers.
+type Match struct {
+	Text      string     // Matched identifier, as it appears in the text
+	Offset    int        // Byte offset of the match in the text
+	Line      int        // 1-based line number
+	Column    int        // 1-based column, counted in bytes
+	Reference *Reference // Parsed identifier
+}
+
+// identifierPattern matches candidate S3 identifiers: S3 URIs, ARNs and
+// HTTP/HTTPS URLs. A candidate ends at whitespace, quotes, angle brackets,
+// backticks, braces, brackets or parentheses.
+var identifierPattern = regexp.MustCompile(
+	`(?i)\b(?:s3[an]?://|arn:aws(?:-cn|-us-gov)?:s3:::|https?://)[^\s"'<>` + "`" + `{}\[\]()|\\^]+`,
+)
+
+// trailingPunctuation is trimmed from the end of a candidate, so that an
+// identifier ending a sentence or a list item does not include the punctuation.
+const trailingPunctuation = ".,;:!?"
+
+// ExtractIdentifiers finds every S3 bucket reference in text: S3 URIs, ARNs,
+// and virtual-hosted-style, path-style, presigned and console URLs. Plain
+// bucket names are not matched, and HTTP/HTTPS URLs only when their host is
+// an S3 or AWS console host. Each match is parsed with the same parsers
+// GetBucketRegion uses; matches with an invalid bucket name are skipped.
+func ExtractIdentifiers(text string) []Match {
+	var matches []Match
+
+	line, lineStart := 1, 0
+	scanned := 0
+	for _, loc := range identifierPattern.FindAllStringIndex(text, -1) {
+		candidate := strings.TrimRight(text[loc[0]:loc[1]], trailingPunctuation)
+
+		ref := parseInput(candidate)
+		if !ref.valid() {
+			continue
+		}
+		if detectFormat(candidate) == formatHTTPURL && !isS3URL(candidate) {
+			continue
+		}
+
+		// Advance the line counter up to the start of this match
+		for i := scanned; i < loc[0]; i++ {
+			if text[i] == '\n' {
+				line++
+				lineStart = i + 1
+			}
+		}
+		scanned = loc[0]
+
+		matches = append(matches, Match{
+			Text:      candidate,
+			Offset:    loc[0],
+			Line:      line,
+			Column:    loc[0] - lineStart + 1,
+			Reference: ref,
+		})
+	}
+	return matches
+}
+
+// isS3URL reports whether rawURL points at an S3 endpoint or an AWS console host.
+func isS3URL(rawURL string) bool {
+	host, _ := splitURLHost(rawURL)
+	if _, ok := parseS3Host(host); ok {
+		return true
+	}
+	return isConsoleHost(host)
+}
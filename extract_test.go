@@ -0,0 +1,70 @@
+package s3region
+
+import (
+	"testing"
+)
+
+func TestExtractIdentifiers(t *testing.T) {
+	text := "Backups go to s3://backup-bucket/daily/ every night.\n" +
+		"IAM resource: \"arn:aws:s3:::logs-bucket/*\",\n" +
+		"See <https://assets-bucket.s3.us-west-2.amazonaws.com/img/logo.png> and\n" +
+		"  https://s3.amazonaws.com/path-bucket/file.txt, or the docs at https://example.com/s3.\n" +
+		"Download: https://dl-bucket.s3.amazonaws.com/a.zip?X-Amz-Algorithm=AWS4-HMAC-SHA256" +
+		"&X-Amz-Credential=AKIA%2F20261016%2Feu-west-1%2Fs3%2Faws4_request&X-Amz-Date=20261016T120000Z" +
+		"&X-Amz-Expires=60&X-Amz-Signature=abc\n" +
+		"Spark reads s3a://lake-bucket/tables (console: https://s3.console.aws.amazon.com/s3/buckets/lake-bucket?region=us-east-2)\n" +
+		"Invalid: s3://BAD_BUCKET/key\n"
+
+	want := []struct {
+		text   string
+		offset int
+		line   int
+		column int
+		bucket string
+	}{
+		{"s3://backup-bucket/daily/", 14, 1, 15, "backup-bucket"},
+		{"arn:aws:s3:::logs-bucket/*", 68, 2, 16, "logs-bucket"},
+		{"https://assets-bucket.s3.us-west-2.amazonaws.com/img/logo.png", 102, 3, 6, "assets-bucket"},
+		{"https://s3.amazonaws.com/path-bucket/file.txt", 171, 4, 3, "path-bucket"},
+		{"", 267, 5, 11, "dl-bucket"},
+		{"s3a://lake-bucket/tables", 0, 6, 13, "lake-bucket"},
+		{"https://s3.console.aws.amazon.com/s3/buckets/lake-bucket?region=us-east-2", 0, 6, 48, "lake-bucket"},
+	}
+
+	matches := ExtractIdentifiers(text)
+	if len(matches) != len(want) {
+		for _, m := range matches {
+			t.Logf("match: %+v", m)
+		}
+		t.Fatalf("ExtractIdentifiers() returned %d matches, want %d", len(matches), len(want))
+	}
+
+	for i, w := range want {
+		m := matches[i]
+		if w.text != "" && m.Text != w.text {
+			t.Errorf("match %d: Text = %q, want %q", i, m.Text, w.text)
+		}
+		if w.offset != 0 && m.Offset != w.offset {
+			t.Errorf("match %d: Offset = %d, want %d", i, m.Offset, w.offset)
+		}
+		if text[m.Offset:m.Offset+len(m.Text)] != m.Text {
+			t.Errorf("match %d: text at Offset = %q, want %q", i, text[m.Offset:m.Offset+len(m.Text)], m.Text)
+		}
+		if m.Line != w.line || m.Column != w.column {
+			t.Errorf("match %d: Line:Column = %d:%d, want %d:%d", i, m.Line, m.Column, w.line, w.column)
+		}
+		if m.Reference.Bucket != w.bucket {
+			t.Errorf("match %d: Bucket = %q, want %q", i, m.Reference.Bucket, w.bucket)
+		}
+	}
+
+	if p := matches[4].Reference.Presign; p == nil || p.Region != "eu-west-1" {
+		t.Errorf("presigned match: Presign = %+v, want region eu-west-1", p)
+	}
+}
+
+func TestExtractIdentifiersEmpty(t *testing.T) {
+	if matches := ExtractIdentifiers("no buckets here, just https://example.com/page"); len(matches) != 0 {
+		t.Errorf("ExtractIdentifiers() = %+v, want no matches", matches)
+	}
+}
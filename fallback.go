@@ -0,0 +1,231 @@
+package s3region
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+)
+
+// defaultFallbacks lists the sources tried when the HEAD response lacks the
+// x-amz-bucket-region header. The redirect sources come first because they
+// need no request beyond the GET /?location the last one sends anyway.
+var defaultFallbacks = []Source{SourceLocationHeader, SourceRedirectEndpoint, SourceLocationConstraint}
+
+// maxBodySize bounds how much of an S3 response body is read.
+const maxBodySize = 64 << 10
+
+// probe holds the responses gathered while looking up the region of one
+// bucket whose HEAD response lacked the x-amz-bucket-region header, so that
+// the GET /?location request is sent at most once.
+type probe struct {
+	ctx    context.Context
+	cfg    *config
+	bucket string
+	head   *response
+	loc    *response
+	locErr error
+
+	answer    *response // Response the region was found in
+	malformed bool      // A successful response could not be parsed
+}
+
+// location returns the response to an anonymous GET /?location request,
+// sending it on first use.
+func (p *probe) location() (*response, error) {
+	if p.loc == nil && p.locErr == nil {
+		url := fmt.Sprintf("https://%s.s3.amazonaws.com/?location", p.bucket)
+		p.loc, p.locErr = send(p.ctx, p.cfg, http.MethodGet, url)
+	}
+	return p.loc, p.locErr
+}
+
+// fallbacks tries the configured fallbacks in order. It reports false if
+// none of them found the region.
+func (p *probe) fallbacks() (string, Source, bool) {
+	triedLocationHeader := false
+	for _, src := range p.cfg.fallbacks {
+		var region string
+		switch src {
+		case SourceLocationHeader:
+			triedLocationHeader = true
+			p.answer = p.head
+			region = redirectLocationRegion(p.head.status, p.head.header)
+			if region == "" && p.loc != nil {
+				p.answer = p.loc
+				region = redirectLocationRegion(p.loc.status, p.loc.header)
+			}
+		case SourceRedirectEndpoint, SourceLocationConstraint:
+			loc, err := p.location()
+			if err != nil {
+				continue
+			}
+			p.answer = loc
+			// The Location header of this response was not seen when
+			// SourceLocationHeader was tried
+			if triedLocationHeader {
+				if region := redirectLocationRegion(loc.status, loc.header); region != "" {
+					return region, SourceLocationHeader, true
+				}
+			}
+			if src == SourceRedirectEndpoint {
+				region = redirectEndpointRegion(loc.body)
+			} else if loc.status == http.StatusOK {
+				var ok bool
+				region, ok = locationConstraintRegion(loc.body)
+				p.malformed = p.malformed || !ok
+			}
+		}
+		if region != "" {
+			return region, src, true
+		}
+	}
+	return "", 0, false
+}
+
+// regionalRetry looks for the bucket's region in the error response to a
+// GET /?location request on the global endpoint, and confirms it with a
+// HEAD request against the regional endpoint. It reports false if no region
+// was found or the regional endpoint refused the request too.
+func (p *probe) regionalRetry() (string, bool) {
+	loc, err := p.location()
+	if err != nil {
+		return "", false
+	}
+	region := strings.TrimSpace(loc.header.Get("x-amz-bucket-region"))
+	if region == "" {
+		region = errorBodyRegion(loc.body)
+	}
+	if region == "" {
+		return "", false
+	}
+
+	resp, err := send(p.ctx, p.cfg, http.MethodHead, endpointURL(p.bucket, "", region))
+	if err != nil {
+		return "", false
+	}
+	p.answer = resp
+	if confirmed := resp.header.Get("x-amz-bucket-region"); confirmed != "" {
+		return strings.TrimSpace(confirmed), true
+	}
+	// The regional endpoint accepted the request for the bucket
+	if resp.status < 300 || resp.status == http.StatusForbidden {
+		return region, true
+	}
+	return "", false
+}
+
+// error wraps err with the metadata of the HEAD response and the S3 error
+// code and message of the GET /?location response, if one was sent.
+func (p *probe) error(err error) error {
+	re := p.head.error(err)
+	if p.loc != nil {
+		var e s3ErrorBody
+		if xml.Unmarshal(p.loc.body, &e) == nil {
+			re.code, re.message = e.Code, e.Message
+		}
+	}
+	switch {
+	case p.locErr != nil:
+		re.err = errors.Join(err, p.locErr)
+	case p.malformed:
+		re.err = errors.Join(err, ErrMalformedResponse)
+	}
+	return re
+}
+
+// redirectLocationRegion returns the region of the S3 endpoint named by the
+// Location header of a 301 or 307 response, or "" if there is none.
+func redirectLocationRegion(status int, header http.Header) string {
+	if status != http.StatusMovedPermanently && status != http.StatusTemporaryRedirect {
+		return ""
+	}
+	u, err := neturl.Parse(header.Get("Location"))
+	if err != nil {
+		return ""
+	}
+	return endpointRegion(u.Host)
+}
+
+// s3ErrorBody is the XML body of an S3 error response.
+type s3ErrorBody struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Endpoint string   `xml:"Endpoint"`
+	Region   string   `xml:"Region"`
+}
+
+// regionInMessage matches a region code in an S3 error message, such as
+// "The me-south-1 location constraint is incompatible for the region
+// specific endpoint this request was sent to."
+var regionInMessage = regexp.MustCompile(`\b[a-z]{2}(?:-gov)?-[a-z]+-[0-9]+\b`)
+
+// errorBodyRegion returns the region named by an S3 error body, either in
+// its Region element or in the message of an IllegalLocationConstraint
+// error, or "" if there is none.
+func errorBodyRegion(body []byte) string {
+	var e s3ErrorBody
+	if err := xml.Unmarshal(body, &e); err != nil {
+		return ""
+	}
+	if e.Region != "" {
+		return strings.TrimSpace(e.Region)
+	}
+	if strings.HasPrefix(e.Code, "IllegalLocationConstraint") {
+		return regionInMessage.FindString(e.Message)
+	}
+	return ""
+}
+
+// redirectEndpointRegion returns the region of the endpoint named by a
+// PermanentRedirect or TemporaryRedirect error body, or "" if body is not
+// one.
+func redirectEndpointRegion(body []byte) string {
+	var e s3ErrorBody
+	if err := xml.Unmarshal(body, &e); err != nil {
+		return ""
+	}
+	if e.Code != "PermanentRedirect" && e.Code != "TemporaryRedirect" {
+		return ""
+	}
+	return endpointRegion(e.Endpoint)
+}
+
+// locationConstraintRegion returns the region named by a GET /?location
+// response body. An empty constraint means us-east-1, and the legacy "EU"
+// constraint eu-west-1. It reports false if body is not a LocationConstraint.
+func locationConstraintRegion(body []byte) (string, bool) {
+	var lc struct {
+		XMLName xml.Name `xml:"LocationConstraint"`
+		Value   string   `xml:",chardata"`
+	}
+	if err := xml.Unmarshal(body, &lc); err != nil {
+		return "", false
+	}
+	switch region := strings.TrimSpace(lc.Value); region {
+	case "":
+		return "us-east-1", true
+	case "EU":
+		return "eu-west-1", true
+	default:
+		return region, true
+	}
+}
+
+// endpointRegion returns the region of an S3 endpoint hostname, or "" if
+// the host is not a regional S3 endpoint.
+func endpointRegion(host string) string {
+	h, ok := parseS3Host(strings.ToLower(host))
+	if !ok {
+		return ""
+	}
+	if h.region != "" {
+		return h.region
+	}
+	return h.regionHint
+}
@@ -0,0 +1,192 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// stubResponse is a canned response served by routeClient.
+type stubResponse struct {
+	status int
+	header map[string]string
+	body   string
+}
+
+// routeClient serves canned responses keyed by "METHOD URL" and records the
+// requests it receives. Unknown requests fail.
+type routeClient struct {
+	routes   map[string]stubResponse
+	requests []string
+}
+
+func (c *routeClient) Do(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + req.URL.String()
+	c.requests = append(c.requests, key)
+
+	r, ok := c.routes[key]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	resp := &http.Response{
+		StatusCode: r.status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(r.body)),
+	}
+	for k, v := range r.header {
+		resp.Header.Set(k, v)
+	}
+	return resp, nil
+}
+
+const (
+	headURL     = "HEAD https://my-bucket.s3.amazonaws.com"
+	locationURL = "GET https://my-bucket.s3.amazonaws.com/?location"
+)
+
+func TestFallbacks(t *testing.T) {
+	permanentRedirect := `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>PermanentRedirect</Code><Message>The bucket you are attempting to access must be addressed using the specified endpoint.</Message><Endpoint>my-bucket.s3.eu-central-1.amazonaws.com</Endpoint><Bucket>my-bucket</Bucket></Error>`
+	temporaryRedirect := `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>TemporaryRedirect</Code><Message>Please re-send this request to the specified temporary endpoint. Continue to use the original request endpoint for future requests.</Message><Endpoint>my-bucket.s3.eu-west-3.amazonaws.com</Endpoint><Bucket>my-bucket</Bucket></Error>`
+
+	tests := []struct {
+		name       string
+		routes     map[string]stubResponse
+		opts       []Option
+		wantRegion string
+		wantSource Source
+		wantErr    error
+	}{
+		{
+			name:       "header",
+			routes:     map[string]stubResponse{headURL: {200, map[string]string{"x-amz-bucket-region": "us-west-2"}, ""}},
+			wantRegion: "us-west-2",
+			wantSource: SourceHeader,
+		},
+		{
+			name: "location header",
+			routes: map[string]stubResponse{
+				headURL: {307, map[string]string{"Location": "https://my-bucket.s3-ap-southeast-2.amazonaws.com/"}, ""},
+			},
+			wantRegion: "ap-southeast-2",
+			wantSource: SourceLocationHeader,
+		},
+		{
+			name: "permanent redirect endpoint",
+			routes: map[string]stubResponse{
+				headURL:     {301, nil, ""},
+				locationURL: {301, nil, permanentRedirect},
+			},
+			wantRegion: "eu-central-1",
+			wantSource: SourceRedirectEndpoint,
+		},
+		{
+			name: "temporary redirect of location request",
+			routes: map[string]stubResponse{
+				headURL:     {307, nil, ""},
+				locationURL: {307, map[string]string{"Location": "https://my-bucket.s3.eu-west-3.amazonaws.com/?location"}, temporaryRedirect},
+			},
+			wantRegion: "eu-west-3",
+			wantSource: SourceLocationHeader,
+		},
+		{
+			name: "temporary redirect endpoint",
+			routes: map[string]stubResponse{
+				headURL:     {307, nil, ""},
+				locationURL: {307, nil, temporaryRedirect},
+			},
+			opts:       []Option{WithFallbacks(SourceRedirectEndpoint)},
+			wantRegion: "eu-west-3",
+			wantSource: SourceRedirectEndpoint,
+		},
+		{
+			name: "location constraint",
+			routes: map[string]stubResponse{
+				headURL:     {200, nil, ""},
+				locationURL: {200, nil, `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">sa-east-1</LocationConstraint>`},
+			},
+			wantRegion: "sa-east-1",
+			wantSource: SourceLocationConstraint,
+		},
+		{
+			name: "empty location constraint",
+			routes: map[string]stubResponse{
+				headURL:     {200, nil, ""},
+				locationURL: {200, nil, `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`},
+			},
+			wantRegion: "us-east-1",
+			wantSource: SourceLocationConstraint,
+		},
+		{
+			name: "EU location constraint",
+			routes: map[string]stubResponse{
+				headURL:     {200, nil, ""},
+				locationURL: {200, nil, `<LocationConstraint>EU</LocationConstraint>`},
+			},
+			wantRegion: "eu-west-1",
+			wantSource: SourceLocationConstraint,
+		},
+		{
+			name: "configured order",
+			routes: map[string]stubResponse{
+				headURL:     {307, map[string]string{"Location": "https://my-bucket.s3.us-east-2.amazonaws.com/"}, ""},
+				locationURL: {200, nil, `<LocationConstraint>ca-central-1</LocationConstraint>`},
+			},
+			opts:       []Option{WithFallbacks(SourceLocationConstraint, SourceLocationHeader)},
+			wantRegion: "ca-central-1",
+			wantSource: SourceLocationConstraint,
+		},
+		{
+			name:    "fallbacks disabled",
+			routes:  map[string]stubResponse{headURL: {200, nil, ""}},
+			opts:    []Option{WithFallbacks()},
+			wantErr: ErrRegionHeaderNotFound,
+		},
+		{
+			name:    "all fallbacks fail",
+			routes:  map[string]stubResponse{headURL: {200, nil, ""}},
+			wantErr: ErrRegionHeaderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &routeClient{routes: tt.routes}
+			opts := append([]Option{WithHTTPClient(client)}, tt.opts...)
+
+			res, err := LookupBucketRegion(context.Background(), "my-bucket", opts...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("LookupBucketRegion() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupBucketRegion() error = %v", err)
+			}
+			if res.Region != tt.wantRegion || res.Source != tt.wantSource {
+				t.Errorf("LookupBucketRegion() = %q from %v, want %q from %v",
+					res.Region, res.Source, tt.wantRegion, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestFallbacksShareLocationRequest(t *testing.T) {
+	client := &routeClient{routes: map[string]stubResponse{
+		headURL:     {200, nil, ""},
+		locationURL: {403, nil, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`},
+	}}
+
+	_, err := GetBucketRegionByName(context.Background(), "my-bucket", WithHTTPClient(client))
+	if !errors.Is(err, ErrRegionHeaderNotFound) {
+		t.Fatalf("GetBucketRegionByName() error = %v, want ErrRegionHeaderNotFound", err)
+	}
+	if len(client.requests) != 2 {
+		t.Errorf("requests = %q, want one HEAD and one GET", client.requests)
+	}
+}
@@ -0,0 +1,210 @@
+package s3region
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Style is an S3 identifier or endpoint style produced by Format.
+type Style int
+
+const (
+	StyleS3URI         Style = iota // s3://bucket/key
+	StyleARN                        // arn:aws:s3:::bucket/key
+	StyleVirtualHosted              // https://bucket.s3.us-west-2.amazonaws.com/key
+	StylePathStyle                  // https://s3.us-west-2.amazonaws.com/bucket/key
+	StyleDualStack                  // https://bucket.s3.dualstack.us-west-2.amazonaws.com/key
+	StyleFIPS                       // https://bucket.s3-fips.us-west-2.amazonaws.com/key
+	StyleAccelerate                 // https://bucket.s3-accelerate.amazonaws.com/key
+	StyleWebsite                    // http://bucket.s3-website-us-west-2.amazonaws.com/key
+	StyleConsole                    // https://s3.console.aws.amazon.com/s3/object/bucket?prefix=key&region=us-west-2
+)
+
+// Styles lists every style supported by Format.
+var Styles = []Style{
+	StyleS3URI, StyleARN, StyleVirtualHosted, StylePathStyle, StyleDualStack,
+	StyleFIPS, StyleAccelerate, StyleWebsite, StyleConsole,
+}
+
+// styleNames holds the name of each style, as returned by Style.String.
+var styleNames = map[Style]string{
+	StyleS3URI:         "s3-uri",
+	StyleARN:           "arn",
+	StyleVirtualHosted: "virtual-hosted",
+	StylePathStyle:     "path-style",
+	StyleDualStack:     "dualstack",
+	StyleFIPS:          "fips",
+	StyleAccelerate:    "accelerate",
+	StyleWebsite:       "website",
+	StyleConsole:       "console",
+}
+
+func (s Style) String() string {
+	if name, ok := styleNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// dashWebsiteRegions lists the regions whose website endpoints use the legacy
+// s3-website-<region> form. All other regions use s3-website.<region>.
+var dashWebsiteRegions = map[string]bool{
+	"us-east-1":      true,
+	"us-west-1":      true,
+	"us-west-2":      true,
+	"ap-southeast-1": true,
+	"ap-southeast-2": true,
+	"ap-northeast-1": true,
+	"eu-west-1":      true,
+	"sa-east-1":      true,
+	"us-gov-west-1":  true,
+}
+
+// fipsRegions lists the regions with S3 FIPS endpoints.
+var fipsRegions = map[string]bool{
+	"us-east-1":     true,
+	"us-east-2":     true,
+	"us-west-1":     true,
+	"us-west-2":     true,
+	"ca-central-1":  true,
+	"ca-west-1":     true,
+	"us-gov-east-1": true,
+	"us-gov-west-1": true,
+}
+
+// Format formats a bucket or object reference in the given style. It uses
+// Reference.Bucket, Key, Region (or RegionHint) and Partition; the partition
+// defaults to the region's partition. Formatting is the reverse of parsing:
+// Parse(Format(ref, style)) returns the same bucket, key, region and
+// partition, except for StyleS3URI and StyleAccelerate, which carry no region.
+//
+// Regional styles return ErrUnsupportedStyle if the region is unknown, as do
+// styles that are not available in the partition or region, such as
+// accelerate outside the aws partition or FIPS outside the regions with FIPS
+// endpoints.
+func Format(ref *Reference, style Style) (string, error) {
+	const op = "Format"
+
+	if !isValidBucketName(ref.Bucket) {
+		return "", newError(op, ref.Bucket, ref.Input, ErrInvalidBucketName)
+	}
+
+	region := ref.Region
+	if region == "" {
+		region = ref.RegionHint
+	}
+	partitionID := ref.Partition
+	if partitionID == "" {
+		partitionID = partitionForRegion(region)
+	}
+	p, ok := lookupPartition(partitionID)
+	if !ok {
+		return "", newError(op, ref.Bucket, ref.Input, ErrUnsupportedStyle)
+	}
+
+	s, ok := formatStyle(ref.Bucket, ref.Key, region, p, style)
+	if !ok {
+		return "", newError(op, ref.Bucket, ref.Input, ErrUnsupportedStyle)
+	}
+	return s, nil
+}
+
+// formatStyle formats bucket and key in the given style. It reports false if
+// the style is not available for the region or partition.
+func formatStyle(bucket, key, region string, p partition, style Style) (string, bool) {
+	path := ""
+	if key != "" {
+		path = "/" + escapeKey(key)
+	}
+
+	// The global endpoint only exists in the aws partition
+	endpoint := "s3." + region + "." + p.dnsSuffix
+	if region == "" {
+		endpoint = "s3." + p.dnsSuffix
+	}
+	globalOK := region != "" || p.id == "aws"
+
+	switch style {
+	case StyleS3URI:
+		return joinKey("s3://"+bucket, key), true
+	case StyleARN:
+		return joinKey("arn:"+p.id+":s3:::"+bucket, key), true
+	case StyleVirtualHosted:
+		return "https://" + bucket + "." + endpoint + path, globalOK
+	case StylePathStyle:
+		return "https://" + endpoint + "/" + bucket + path, globalOK
+	case StyleDualStack:
+		return "https://" + bucket + ".s3.dualstack." + region + "." + p.dnsSuffix + path, region != ""
+	case StyleFIPS:
+		return "https://" + bucket + ".s3-fips." + region + "." + p.dnsSuffix + path, fipsRegions[region]
+	case StyleAccelerate:
+		return "https://" + bucket + ".s3-accelerate." + p.dnsSuffix + path, p.id == "aws" && !strings.Contains(bucket, ".")
+	case StyleWebsite:
+		if dashWebsiteRegions[region] {
+			return "http://" + bucket + ".s3-website-" + region + "." + p.dnsSuffix + path, true
+		}
+		return "http://" + bucket + ".s3-website." + region + "." + p.dnsSuffix + path, region != ""
+	case StyleConsole:
+		return consoleURL(bucket, key, region, p), true
+	}
+	return "", false
+}
+
+// consoleURL builds an AWS console link: the bucket view for a bucket or
+// a prefix ending in "/", and the object view for an object key.
+func consoleURL(bucket, key, region string, p partition) string {
+	view := "buckets"
+	if key != "" && !strings.HasSuffix(key, "/") {
+		view = "object"
+	}
+
+	query := url.Values{}
+	if key != "" {
+		query.Set("prefix", key)
+	}
+	if region != "" {
+		query.Set("region", region)
+	}
+
+	// The aws partition serves the S3 console from its own subdomain
+	host := p.consoleDomain
+	if p.id == "aws" {
+		host = "s3." + host
+	}
+
+	u := "https://" + host + "/s3/" + view + "/" + bucket
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// escapeKey percent-encodes each segment of an object key for use in a URL path.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// joinKey appends "/key" to prefix if key is not empty.
+func joinKey(prefix, key string) string {
+	if key == "" {
+		return prefix
+	}
+	return prefix + "/" + key
+}
+
+// endpointURL returns the URL of key in bucket at the regional endpoint of
+// region: virtual-hosted-style, or path-style for bucket names containing
+// dots, which the wildcard certificate of the endpoint does not cover.
+func endpointURL(bucket, key, region string) string {
+	style := StyleVirtualHosted
+	if strings.Contains(bucket, ".") {
+		style = StylePathStyle
+	}
+	pt, _ := lookupPartition(partitionForRegion(region))
+	url, _ := formatStyle(bucket, key, region, pt, style)
+	return url
+}
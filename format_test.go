@@ -0,0 +1,135 @@
+package s3region
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	ref := &Reference{Bucket: "my-bucket", Key: "path/to/my file.txt", Region: "us-west-2"}
+
+	tests := []struct {
+		style Style
+		want  string
+	}{
+		{StyleS3URI, "s3://my-bucket/path/to/my file.txt"},
+		{StyleARN, "arn:aws:s3:::my-bucket/path/to/my file.txt"},
+		{StyleVirtualHosted, "https://my-bucket.s3.us-west-2.amazonaws.com/path/to/my%20file.txt"},
+		{StylePathStyle, "https://s3.us-west-2.amazonaws.com/my-bucket/path/to/my%20file.txt"},
+		{StyleDualStack, "https://my-bucket.s3.dualstack.us-west-2.amazonaws.com/path/to/my%20file.txt"},
+		{StyleFIPS, "https://my-bucket.s3-fips.us-west-2.amazonaws.com/path/to/my%20file.txt"},
+		{StyleAccelerate, "https://my-bucket.s3-accelerate.amazonaws.com/path/to/my%20file.txt"},
+		{StyleWebsite, "http://my-bucket.s3-website-us-west-2.amazonaws.com/path/to/my%20file.txt"},
+		{StyleConsole, "https://s3.console.aws.amazon.com/s3/object/my-bucket?prefix=path%2Fto%2Fmy+file.txt&region=us-west-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style.String(), func(t *testing.T) {
+			got, err := Format(ref, tt.style)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPartitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		ref   *Reference
+		style Style
+		want  string
+	}{
+		{"china virtual-hosted", &Reference{Bucket: "my-bucket", Region: "cn-north-1"}, StyleVirtualHosted, "https://my-bucket.s3.cn-north-1.amazonaws.com.cn"},
+		{"china arn", &Reference{Bucket: "my-bucket", Region: "cn-north-1"}, StyleARN, "arn:aws-cn:s3:::my-bucket"},
+		{"china console", &Reference{Bucket: "my-bucket", Region: "cn-northwest-1"}, StyleConsole, "https://console.amazonaws.cn/s3/buckets/my-bucket?region=cn-northwest-1"},
+		{"china website", &Reference{Bucket: "my-bucket", Region: "cn-north-1"}, StyleWebsite, "http://my-bucket.s3-website.cn-north-1.amazonaws.com.cn"},
+		{"govcloud arn", &Reference{Bucket: "my-bucket", Region: "us-gov-west-1"}, StyleARN, "arn:aws-us-gov:s3:::my-bucket"},
+		{"govcloud fips", &Reference{Bucket: "my-bucket", Key: "k", Region: "us-gov-east-1"}, StyleFIPS, "https://my-bucket.s3-fips.us-gov-east-1.amazonaws.com/k"},
+		{"govcloud console", &Reference{Bucket: "my-bucket", Key: "logs/", Region: "us-gov-west-1"}, StyleConsole, "https://console.amazonaws-us-gov.com/s3/buckets/my-bucket?prefix=logs%2F&region=us-gov-west-1"},
+		{"global endpoint without region", &Reference{Bucket: "my-bucket", Key: "k"}, StylePathStyle, "https://s3.amazonaws.com/my-bucket/k"},
+		{"dot-style website region", &Reference{Bucket: "my-bucket", RegionHint: "eu-central-1"}, StyleWebsite, "http://my-bucket.s3-website.eu-central-1.amazonaws.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.ref, tt.style)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatUnsupported(t *testing.T) {
+	tests := []struct {
+		name  string
+		ref   *Reference
+		style Style
+	}{
+		{"accelerate in china", &Reference{Bucket: "my-bucket", Region: "cn-north-1"}, StyleAccelerate},
+		{"accelerate with dotted bucket", &Reference{Bucket: "my.bucket", Region: "us-east-1"}, StyleAccelerate},
+		{"fips in china", &Reference{Bucket: "my-bucket", Region: "cn-north-1"}, StyleFIPS},
+		{"fips in eu-west-1", &Reference{Bucket: "b-b-b", Region: "eu-west-1"}, StyleFIPS},
+		{"fips in ap-south-1", &Reference{Bucket: "my-bucket", Region: "ap-south-1"}, StyleFIPS},
+		{"dualstack without region", &Reference{Bucket: "my-bucket"}, StyleDualStack},
+		{"website without region", &Reference{Bucket: "my-bucket"}, StyleWebsite},
+		{"global endpoint in china", &Reference{Bucket: "my-bucket", Partition: "aws-cn"}, StyleVirtualHosted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Format(tt.ref, tt.style); !errors.Is(err, ErrUnsupportedStyle) {
+				t.Errorf("Format() error = %v, want %v", err, ErrUnsupportedStyle)
+			}
+		})
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	refs := []*Reference{
+		{Bucket: "my-bucket", Key: "path/to/my file+1.txt", Region: "us-west-2"},
+		{Bucket: "my-bucket", Key: "logs/2026/", Region: "eu-central-1"},
+		{Bucket: "my-bucket", Region: "cn-north-1"},
+		{Bucket: "my-bucket", Key: "data.csv", Region: "us-gov-west-1"},
+	}
+
+	for _, ref := range refs {
+		for _, style := range Styles {
+			formatted, err := Format(ref, style)
+			if errors.Is(err, ErrUnsupportedStyle) {
+				continue
+			}
+			if err != nil {
+				t.Fatalf("Format(%+v, %v) error = %v", ref, style, err)
+			}
+
+			got, err := Parse(formatted)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", formatted, err)
+			}
+			if got.Bucket != ref.Bucket || got.Key != ref.Key {
+				t.Errorf("Parse(%q) = %q, %q, want %q, %q", formatted, got.Bucket, got.Key, ref.Bucket, ref.Key)
+			}
+			if style == StyleS3URI || style == StyleAccelerate {
+				continue
+			}
+			region := got.Region
+			if region == "" {
+				region = got.RegionHint
+			}
+			if style != StyleARN && region != ref.Region {
+				t.Errorf("Parse(%q) region = %q, want %q", formatted, region, ref.Region)
+			}
+			if got.Partition != partitionForRegion(ref.Region) {
+				t.Errorf("Parse(%q) Partition = %q, want %q", formatted, got.Partition, partitionForRegion(ref.Region))
+			}
+		}
+	}
+}
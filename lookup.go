@@ -0,0 +1,354 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source identifies where a lookup found a bucket's region.
+type Source int
+
+const (
+	SourceHeader             Source = iota // x-amz-bucket-region header of the HEAD response
+	SourceLocationHeader                   // Location header of a 301 or 307 response
+	SourceRedirectEndpoint                 // Endpoint of a PermanentRedirect or TemporaryRedirect error body
+	SourceLocationConstraint               // LocationConstraint of an anonymous GET /?location
+	SourceDNS                              // CNAME chain of the global endpoint
+	SourceRegionalEndpoint                 // Regional endpoint accepting a request the global one refused
+	SourceHint                             // Region carried by the input, such as a VPC endpoint hostname
+	SourceCache                            // Earlier lookup remembered by the Cache set with WithCache
+)
+
+// String returns the name of the source, such as "header" or "dns".
+func (s Source) String() string {
+	switch s {
+	case SourceHeader:
+		return "header"
+	case SourceLocationHeader:
+		return "location-header"
+	case SourceRedirectEndpoint:
+		return "redirect-endpoint"
+	case SourceLocationConstraint:
+		return "location-constraint"
+	case SourceDNS:
+		return "dns"
+	case SourceRegionalEndpoint:
+		return "regional-endpoint"
+	case SourceHint:
+		return "hint"
+	case SourceCache:
+		return "cache"
+	}
+	return "unknown"
+}
+
+// Access classifies the anonymous access a bucket allowed during a lookup.
+type Access int
+
+const (
+	AccessUnknown Access = iota // No response tells, such as a redirect or a DNS lookup
+	AccessPublic                // The anonymous request succeeded (200)
+	AccessPrivate               // The anonymous request was denied (403)
+)
+
+// String returns the name of the access class, such as "public".
+func (a Access) String() string {
+	switch a {
+	case AccessPublic:
+		return "public"
+	case AccessPrivate:
+		return "private"
+	}
+	return "unknown"
+}
+
+// Result is the outcome of a bucket region lookup.
+type Result struct {
+	Input     string // Input provided by the user
+	Alias     string // Alias the input was expanded from, if any
+	Prefix    string // Text stripped before the identifier in ParseLenient mode
+	Suffix    string // Text stripped after the identifier in ParseLenient mode
+	Bucket    string // Bucket name, empty for access points
+	Key       string // Object key or prefix, if any, as resolved for custom domains
+	Region    string // Region the bucket lives in
+	Partition string // AWS partition of the region
+	Source    Source // Where the region was found
+	Exists    bool   // The response proves that the bucket exists
+	Access    Access // Anonymous access the response shows
+
+	// Metadata of the S3 response the region was found in, empty for
+	// SourceDNS, SourceHint and SourceCache
+	Endpoint   string        // URL of the request
+	StatusCode int           // HTTP status
+	RequestID  string        // x-amz-request-id header
+	HostID     string        // x-amz-id-2 header
+	Attempts   int           // Attempts made for the request, see WithRetry
+	Elapsed    time.Duration // Time the whole lookup took
+
+	// Headers describing directory buckets and access points, if present
+	LocationType     string // x-amz-bucket-location-type, such as "AvailabilityZone"
+	LocationName     string // x-amz-bucket-location-name, such as "usw2-az1"
+	AccessPointAlias bool   // x-amz-access-point-alias: the bucket name is an access point alias
+}
+
+// setResponse fills the result from the S3 response the region was found in.
+func (r *Result) setResponse(resp *response) {
+	r.Endpoint, r.StatusCode, r.Attempts = resp.url, resp.status, resp.attempts
+	r.RequestID, r.HostID = resp.requestID(), resp.hostID()
+	r.Exists = resp.status != 404
+	switch resp.status {
+	case 200:
+		r.Access = AccessPublic
+	case 403:
+		r.Access = AccessPrivate
+	}
+	r.LocationType = resp.header.Get("x-amz-bucket-location-type")
+	r.LocationName = resp.header.Get("x-amz-bucket-location-name")
+	r.AccessPointAlias = strings.EqualFold(resp.header.Get("x-amz-access-point-alias"), "true")
+}
+
+// Cache remembers the results of successful lookups by bucket name, so that
+// each bucket is looked up once. It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	results map[string]Result
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{results: make(map[string]Result)}
+}
+
+func (c *Cache) get(bucket string) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res, ok := c.results[bucket]
+	return res, ok
+}
+
+func (c *Cache) put(res Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[res.Bucket] = res
+}
+
+// Lookup accepts any S3 identifier format accepted by GetBucketRegion and
+// returns everything the lookup found out about the bucket: its region and
+// partition, where the region was found, whether the bucket exists and
+// allows anonymous access, and the metadata of the S3 response.
+func Lookup(ctx context.Context, input string, opts ...Option) (*Result, error) {
+	return lookupInput(ctx, "Lookup", input, opts)
+}
+
+// LookupBucketRegion is Lookup for a bucket name.
+func LookupBucketRegion(ctx context.Context, bucketName string, opts ...Option) (*Result, error) {
+	return lookupByName(ctx, newConfig(opts), "LookupBucketRegion", bucketName)
+}
+
+// lookupInput detects the format of input and looks up its bucket. Errors
+// not raised by a format-specific lookup are reported under op.
+func lookupInput(ctx context.Context, op, input string, opts []Option) (*Result, error) {
+	cfg := newConfig(opts)
+	if cfg.parseMode == ParseLenient {
+		if clean, prefix, suffix := sanitize(input); clean != input {
+			res, err := lookupInput(ctx, op, clean, opts)
+			if err != nil {
+				return nil, newError(op, parseInput(clean).Bucket, input, err)
+			}
+			res.Input, res.Prefix, res.Suffix = input, prefix, suffix
+			return res, nil
+		}
+	}
+
+	if target, alias, ok := cfg.resolveAlias(input); ok {
+		res, err := lookupInput(ctx, op, target, withoutAliases(opts))
+		if err != nil {
+			return nil, newError(op, parseInput(target).Bucket, input, err)
+		}
+		res.Input, res.Alias = input, alias
+		return res, nil
+	}
+
+	if ref, ok, err := parseRegistered(input); ok {
+		if err != nil {
+			return nil, newError(op, "", input, err)
+		}
+		res, err := lookupReference(ctx, ref, opts)
+		if err != nil {
+			return nil, newError(op, ref.Bucket, input, err)
+		}
+		return res, nil
+	}
+
+	if cfg.parseMode == ParseStrict {
+		if err := checkStrict(input, cfg); err != nil {
+			return nil, newError(op, "", input, err)
+		}
+	}
+
+	switch detectFormat(input) {
+	case formatARN:
+		return lookupARN(ctx, input, opts)
+	case formatS3URI:
+		return lookupS3URI(ctx, input, opts)
+	case formatHTTPURL:
+		return lookupHTTPURL(ctx, input, opts)
+	}
+
+	// Handle plain bucket name with or without path
+	bucketName, key := splitBucketPath(input)
+	res, err := lookupByName(ctx, cfg, "GetBucketRegionByName", bucketName)
+	if err != nil && input != bucketName {
+		// Wrap error to include original input if it had a path
+		return nil, newError(op, bucketName, input, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.Input, res.Key = input, key
+	return res, nil
+}
+
+// lookupARN looks up the bucket of an S3 ARN.
+func lookupARN(ctx context.Context, arn string, opts []Option) (*Result, error) {
+	const op = "GetBucketRegionFromARN"
+
+	ref := parseARN(arn)
+	res, err := lookupReference(ctx, ref, opts)
+	if err != nil {
+		return nil, newError(op, ref.Bucket, arn, err)
+	}
+	return res, nil
+}
+
+// lookupS3URI looks up the bucket of an S3 URI.
+func lookupS3URI(ctx context.Context, uri string, opts []Option) (*Result, error) {
+	const op = "GetBucketRegionFromS3URI"
+
+	ref := parseS3URI(uri)
+	res, err := lookupReference(ctx, ref, opts)
+	if err != nil {
+		return nil, newError(op, ref.Bucket, uri, err)
+	}
+	return res, nil
+}
+
+// lookupHTTPURL looks up the bucket of an HTTP/HTTPS URL, following the
+// CNAME chain of a custom domain if enabled.
+func lookupHTTPURL(ctx context.Context, url string, opts []Option) (*Result, error) {
+	const op = "GetBucketRegionFromHTTPURL"
+
+	ref := parseHTTPURL(url)
+	if cfg := newConfig(opts); cfg.followCNAME {
+		if err := resolveCustomDomain(ctx, ref, cfg); err != nil {
+			return nil, newError(op, "", url, err)
+		}
+	}
+	res, err := lookupReference(ctx, ref, opts)
+	if err != nil {
+		return nil, newError(op, ref.Bucket, url, err)
+	}
+	return res, nil
+}
+
+// lookupReference looks up the bucket of a parsed reference. A region pinned
+// by the endpoint is returned as is, a region hint only when hints are
+// enabled, and otherwise the bucket is looked up by name. The partition of
+// the input is only used for a region taken from it.
+func lookupReference(ctx context.Context, ref *Reference, opts []Option) (*Result, error) {
+	cfg := newConfig(opts)
+
+	region := ref.Region
+	if region == "" && cfg.regionHints {
+		region = ref.RegionHint
+	}
+
+	var res *Result
+	if region == "" {
+		var err error
+		res, err = lookupByName(ctx, cfg, "GetBucketRegionByName", ref.Bucket)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if !ref.valid() {
+			return nil, ErrInvalidBucketName
+		}
+		res = &Result{Bucket: ref.Bucket, Region: region, Partition: ref.Partition, Source: SourceHint}
+		if res.Partition == "" {
+			res.Partition = partitionForRegion(region)
+		}
+	}
+
+	res.Input, res.Key = ref.Input, ref.Key
+	return res, nil
+}
+
+// lookupByName validates bucketName and looks up its region with the
+// configured strategy. Errors are reported under op.
+func lookupByName(ctx context.Context, cfg *config, op, bucketName string) (*Result, error) {
+	start := time.Now()
+	if !isValidBucketName(bucketName) {
+		return nil, newError(op, bucketName, bucketName, ErrInvalidBucketName)
+	}
+	if cfg.cache != nil {
+		if cached, ok := cfg.cache.get(bucketName); ok {
+			res := &Result{
+				Input:     bucketName,
+				Bucket:    bucketName,
+				Region:    cached.Region,
+				Partition: cached.Partition,
+				Source:    SourceCache,
+				Exists:    cached.Exists,
+				Access:    cached.Access,
+				Elapsed:   time.Since(start),
+			}
+			return res, nil
+		}
+	}
+
+	res := &Result{Input: bucketName, Bucket: bucketName}
+	var resp *response
+	var err error
+	switch cfg.strategy {
+	case StrategyDNS:
+		res.Region, res.Source, resp, err = dnsLookup(ctx, cfg, bucketName)
+	case StrategyDNSThenHTTP:
+		res.Region, res.Source, resp, err = dnsLookup(ctx, cfg, bucketName)
+		if err != nil {
+			res.Region, res.Source, resp, err = headRegion(ctx, cfg, bucketName)
+		}
+	case StrategyHTTPThenDNS:
+		res.Region, res.Source, resp, err = headRegion(ctx, cfg, bucketName)
+		if err != nil && !errors.Is(err, ErrBucketNotFound) {
+			if r, dnsErr := dnsRegion(ctx, cfg, bucketName); dnsErr == nil {
+				res.Region, res.Source, err = r, SourceDNS, nil
+			}
+		}
+	default:
+		res.Region, res.Source, resp, err = headRegion(ctx, cfg, bucketName)
+	}
+	if err != nil {
+		err = newError(op, bucketName, bucketName, err)
+		err.(*Error).Elapsed = time.Since(start)
+		return nil, err
+	}
+	if resp != nil {
+		res.setResponse(resp)
+	}
+	res.Partition = partitionForRegion(res.Region)
+	res.Elapsed = time.Since(start)
+	if cfg.cache != nil {
+		cfg.cache.put(*res)
+	}
+	return res, nil
+}
+
+// dnsLookup is dnsRegion reporting SourceDNS and no response.
+func dnsLookup(ctx context.Context, cfg *config, bucketName string) (string, Source, *response, error) {
+	region, err := dnsRegion(ctx, cfg, bucketName)
+	return region, SourceDNS, nil, err
+}
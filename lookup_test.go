@@ -0,0 +1,161 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLookupMetadata(t *testing.T) {
+	ids := map[string]string{"x-amz-request-id": "4442587FB7D0A2F9", "x-amz-id-2": "vlR7PnpV2Ce81l0PRw6jlUpck7Jo5ZsQjryTjKlc5aLWGVHPZLj5NeC6qMa0emYBDXOo6QBU0Wo="}
+
+	success := map[string]string{"x-amz-bucket-region": "eu-west-2"}
+	for k, v := range ids {
+		success[k] = v
+	}
+	client := &routeClient{routes: map[string]stubResponse{headURL: {200, success, ""}}}
+	res, err := LookupBucketRegion(context.Background(), "my-bucket", WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("LookupBucketRegion() error = %v", err)
+	}
+	if res.Endpoint != "https://my-bucket.s3.amazonaws.com" || res.StatusCode != 200 || res.Attempts != 1 {
+		t.Errorf("Result = %q %d %d attempts, want the global endpoint, 200, 1 attempt", res.Endpoint, res.StatusCode, res.Attempts)
+	}
+	if res.RequestID != ids["x-amz-request-id"] || res.HostID != ids["x-amz-id-2"] {
+		t.Errorf("Result IDs = %q, %q, want %q, %q", res.RequestID, res.HostID, ids["x-amz-request-id"], ids["x-amz-id-2"])
+	}
+	if res.Elapsed <= 0 {
+		t.Errorf("Elapsed = %v, want > 0", res.Elapsed)
+	}
+
+	client = &routeClient{routes: map[string]stubResponse{headURL: {500, ids, ""}}}
+	_, err = GetBucketRegion(context.Background(), "s3://my-bucket/key", WithHTTPClient(client))
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("GetBucketRegion() error = %v, want *Error", err)
+	}
+	if e.Endpoint != "https://my-bucket.s3.amazonaws.com" || e.StatusCode != 500 {
+		t.Errorf("Error = %q %d, want the global endpoint and 500", e.Endpoint, e.StatusCode)
+	}
+	if e.RequestID != ids["x-amz-request-id"] || e.HostID != ids["x-amz-id-2"] {
+		t.Errorf("Error IDs = %q, %q, want %q, %q", e.RequestID, e.HostID, ids["x-amz-request-id"], ids["x-amz-id-2"])
+	}
+	if e.Elapsed <= 0 {
+		t.Errorf("Elapsed = %v, want > 0", e.Elapsed)
+	}
+	// The message stays concise
+	if strings.Contains(err.Error(), e.HostID) || strings.Contains(err.Error(), e.RequestID) {
+		t.Errorf("Error() = %q, want no request IDs", err.Error())
+	}
+}
+
+func TestLookupMetadataNetworkError(t *testing.T) {
+	_, err := LookupBucketRegion(context.Background(), "my-bucket", WithHTTPClient(&failingHTTPClient{}))
+	var e *Error
+	if !errors.As(err, &e) || e.Endpoint != "https://my-bucket.s3.amazonaws.com" || e.StatusCode != 0 || e.Attempts != 1 {
+		t.Errorf("error = %v, want the endpoint contacted and no status", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	region := func(r string, extra ...string) map[string]string {
+		h := map[string]string{"x-amz-bucket-region": r}
+		for i := 0; i+1 < len(extra); i += 2 {
+			h[extra[i]] = extra[i+1]
+		}
+		return h
+	}
+
+	tests := []struct {
+		name   string
+		input  string
+		opts   []Option
+		routes map[string]stubResponse
+		want   Result
+	}{
+		{
+			name:   "public bucket",
+			input:  "s3://my-bucket/key",
+			routes: map[string]stubResponse{headURL: {200, region("eu-west-1"), ""}},
+			want:   Result{Input: "s3://my-bucket/key", Bucket: "my-bucket", Key: "key", Region: "eu-west-1", Partition: "aws", Source: SourceHeader, Exists: true, Access: AccessPublic},
+		},
+		{
+			name:   "private bucket",
+			input:  "arn:aws:s3:::my-bucket",
+			routes: map[string]stubResponse{headURL: {403, region("us-gov-west-1"), ""}},
+			want:   Result{Input: "arn:aws:s3:::my-bucket", Bucket: "my-bucket", Region: "us-gov-west-1", Partition: "aws-us-gov", Source: SourceHeader, Exists: true, Access: AccessPrivate},
+		},
+		{
+			name:   "redirect",
+			input:  "my-bucket",
+			routes: map[string]stubResponse{headURL: {301, region("ap-south-1"), ""}},
+			want:   Result{Input: "my-bucket", Bucket: "my-bucket", Region: "ap-south-1", Partition: "aws", Source: SourceHeader, Exists: true, Access: AccessUnknown},
+		},
+		{
+			name:  "directory bucket headers",
+			input: "my-bucket",
+			routes: map[string]stubResponse{headURL: {200, region("us-west-2",
+				"x-amz-bucket-location-type", "AvailabilityZone", "x-amz-bucket-location-name", "usw2-az1"), ""}},
+			want: Result{Input: "my-bucket", Bucket: "my-bucket", Region: "us-west-2", Partition: "aws", Source: SourceHeader, Exists: true, Access: AccessPublic,
+				LocationType: "AvailabilityZone", LocationName: "usw2-az1"},
+		},
+		{
+			name:  "access point alias",
+			input: "my-bucket",
+			routes: map[string]stubResponse{headURL: {200, region("us-east-1",
+				"x-amz-access-point-alias", "true"), ""}},
+			want: Result{Input: "my-bucket", Bucket: "my-bucket", Region: "us-east-1", Partition: "aws", Source: SourceHeader, Exists: true, Access: AccessPublic,
+				AccessPointAlias: true},
+		},
+		{
+			name:  "pinned region",
+			input: "https://my-bucket.bucket.vpce-1a2b3c4d-5e6f.s3.cn-north-1.vpce.amazonaws.com.cn/key",
+			want: Result{Input: "https://my-bucket.bucket.vpce-1a2b3c4d-5e6f.s3.cn-north-1.vpce.amazonaws.com.cn/key",
+				Bucket: "my-bucket", Key: "key", Region: "cn-north-1", Partition: "aws-cn", Source: SourceHint},
+		},
+		{
+			name:   "alias",
+			input:  "logs/2026",
+			opts:   []Option{WithAliases(map[string]string{"logs": "s3://my-bucket/app/"})},
+			routes: map[string]stubResponse{headURL: {200, region("sa-east-1"), ""}},
+			want:   Result{Input: "logs/2026", Alias: "logs", Bucket: "my-bucket", Key: "app/2026", Region: "sa-east-1", Partition: "aws", Source: SourceHeader, Exists: true, Access: AccessPublic},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &routeClient{routes: tt.routes}
+			res, err := Lookup(context.Background(), tt.input, append(tt.opts, WithHTTPClient(client))...)
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+			// Compare without the response metadata, covered by TestLookupMetadata
+			got := *res
+			got.Endpoint, got.StatusCode, got.RequestID, got.HostID, got.Attempts, got.Elapsed = "", 0, "", "", 0, 0
+			if got != tt.want {
+				t.Errorf("Lookup() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupCache(t *testing.T) {
+	client := &routeClient{routes: map[string]stubResponse{headURL: {200, map[string]string{"x-amz-bucket-region": "eu-north-1"}, ""}}}
+	opts := []Option{WithHTTPClient(client), WithCache(NewCache())}
+
+	first, err := Lookup(context.Background(), "s3://my-bucket/a", opts...)
+	if err != nil || first.Source != SourceHeader {
+		t.Fatalf("first Lookup() = %+v, %v", first, err)
+	}
+	second, err := Lookup(context.Background(), "https://my-bucket.s3.amazonaws.com/b", opts...)
+	if err != nil {
+		t.Fatalf("second Lookup() error = %v", err)
+	}
+	if second.Source != SourceCache || second.Region != "eu-north-1" || second.Input != "https://my-bucket.s3.amazonaws.com/b" {
+		t.Errorf("second Lookup() = %+v, want eu-north-1 from cache", second)
+	}
+	if len(client.requests) != 1 {
+		t.Errorf("requests = %q, want one", client.requests)
+	}
+}
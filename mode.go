@@ -0,0 +1,113 @@
+package s3region
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// markdownLink matches a markdown link or image, [text](target), capturing
+// the target.
+var markdownLink = regexp.MustCompile(`^!?\[[^\]]*\]\(([^()\s]+)\)$`)
+
+// enclosingPairs lists the opening and closing delimiters stripped in
+// ParseLenient mode when they enclose the whole input.
+var enclosingPairs = []string{`""`, `''`, "``", "<>", "()", "[]", "{}", "__"}
+
+// Characters stripped from either end of the input in ParseLenient mode.
+// Leading "-", "*" and ">" are markdown list and quote markers.
+const (
+	leadingJunk  = `"'<>-*` + "`"
+	trailingJunk = `"'>*` + "`" + trailingPunctuation
+)
+
+// strictRejected lists the characters that make an input ambiguous in
+// ParseStrict mode, in addition to whitespace.
+const strictRejected = `"'<>` + "`"
+
+// sanitize strips whitespace, quotes, enclosing brackets, unmatched closing
+// brackets, trailing punctuation and markdown link syntax around input, repeating until nothing
+// more can be removed. It returns the remaining identifier and the removed
+// leading and trailing text.
+func sanitize(input string) (clean, prefix, suffix string) {
+	start, end := 0, len(input)
+	for {
+		s := input[start:end]
+		newStart, newEnd := start, end
+
+		if loc := markdownLink.FindStringSubmatchIndex(s); loc != nil {
+			newStart, newEnd = start+loc[2], start+loc[3]
+		} else if isEnclosed(s) {
+			newStart, newEnd = start+1, end-1
+		} else {
+			trimmed := strings.TrimLeftFunc(s, func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(leadingJunk, r)
+			})
+			newStart = start + len(s) - len(trimmed)
+			newEnd = newStart + len(strings.TrimRightFunc(trimmed, func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(trailingJunk, r)
+			}))
+			if newEnd == end && hasUnmatchedCloser(input[newStart:newEnd]) {
+				newEnd--
+			}
+		}
+
+		if newStart == start && newEnd == end {
+			return input[start:end], input[:start], input[end:]
+		}
+		start, end = newStart, newEnd
+	}
+}
+
+// hasUnmatchedCloser reports whether s ends with a ")" or "]" that has no
+// opening counterpart in s, such as the end of a parenthetical in prose.
+func hasUnmatchedCloser(s string) bool {
+	if s == "" {
+		return false
+	}
+	switch closer := s[len(s)-1]; closer {
+	case ')':
+		return strings.Count(s, "(") < strings.Count(s, ")")
+	case ']':
+		return strings.Count(s, "[") < strings.Count(s, "]")
+	}
+	return false
+}
+
+// isEnclosed reports whether s starts and ends with a pair of delimiters
+// from enclosingPairs.
+func isEnclosed(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	for _, pair := range enclosingPairs {
+		if s[0] == pair[0] && s[len(s)-1] == pair[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkStrict returns an error wrapping ErrAmbiguousInput that describes why
+// input is ambiguous, or nil if it is a clean identifier.
+func checkStrict(input string, cfg *config) error {
+	for i, r := range input {
+		if unicode.IsSpace(r) || strings.ContainsRune(strictRejected, r) {
+			return fmt.Errorf("%w: unexpected %q at offset %d", ErrAmbiguousInput, r, i)
+		}
+	}
+
+	switch detectFormat(input) {
+	case formatName:
+		if bucket, _ := splitBucketPath(input); bucket != input {
+			return fmt.Errorf("%w: bucket name %q followed by a path, use an s3:// URI", ErrAmbiguousInput, bucket)
+		}
+	case formatHTTPURL:
+		if !isS3URL(input) && !cfg.followCNAME {
+			host, _ := splitURLHost(input)
+			return fmt.Errorf("%w: host %q is not an S3 endpoint", ErrAmbiguousInput, host)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseLenient(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantBucket string
+		wantKey    string
+		wantPrefix string
+		wantSuffix string
+	}{
+		{"s3://bucket/key", "bucket", "key", "", ""},
+		{`"s3://bucket/key",`, "bucket", "key", `"`, `",`},
+		{"  my-bucket\n", "my-bucket", "", "  ", "\n"},
+		{"<https://bucket.s3.amazonaws.com/a/b>", "bucket", "a/b", "<", ">"},
+		{"`arn:aws:s3:::bucket/key`.", "bucket", "key", "`", "`."},
+		{"(s3://bucket/logs/).", "bucket", "logs/", "(", ")."},
+		{"[docs](https://s3.us-west-2.amazonaws.com/bucket/index.html)", "bucket", "index.html", "[docs](", ")"},
+		{"- **s3://bucket/key**", "bucket", "key", "- **", "**"},
+		{"> _s3://bucket/key_", "bucket", "key", "> _", "_"},
+		{"s3://bucket/key)", "bucket", "key", "", ")"},
+		{"https://bucket.s3.amazonaws.com/key]).", "bucket", "key", "", "])."},
+		{"s3://bucket/a(1)", "bucket", "a(1)", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ref, err := Parse(tt.input, WithParseMode(ParseLenient))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if ref.Bucket != tt.wantBucket || ref.Key != tt.wantKey {
+				t.Errorf("Parse() = %q, %q, want %q, %q", ref.Bucket, ref.Key, tt.wantBucket, tt.wantKey)
+			}
+			if ref.Prefix != tt.wantPrefix || ref.Suffix != tt.wantSuffix {
+				t.Errorf("stripped %q, %q, want %q, %q", ref.Prefix, ref.Suffix, tt.wantPrefix, tt.wantSuffix)
+			}
+			if ref.Input != tt.input {
+				t.Errorf("Input = %q, want %q", ref.Input, tt.input)
+			}
+		})
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	valid := []string{
+		"my-bucket",
+		"s3://my-bucket/path/to/key",
+		"arn:aws:s3:::my-bucket/key",
+		"https://my-bucket.s3.us-west-2.amazonaws.com/key",
+		"https://s3.amazonaws.com/my-bucket/key",
+	}
+	for _, input := range valid {
+		if _, err := Parse(input, WithParseMode(ParseStrict)); err != nil {
+			t.Errorf("Parse(%q) error = %v", input, err)
+		}
+	}
+
+	ambiguous := []string{
+		"my-bucket/any/path",
+		`"s3://my-bucket/key"`,
+		" my-bucket",
+		"https://my-bucket.example.com",
+		"https://cdn.example.com/my-bucket/key",
+		"https://my-bucket.s3.example.net/key",
+	}
+	for _, input := range ambiguous {
+		_, err := Parse(input, WithParseMode(ParseStrict))
+		if !errors.Is(err, ErrAmbiguousInput) {
+			t.Errorf("Parse(%q) error = %v, want ErrAmbiguousInput", input, err)
+		}
+	}
+
+	// The expanded target of an alias is checked too
+	opts := []Option{WithParseMode(ParseStrict), WithAliases(map[string]string{"prod-logs": "acme-prod-logs"})}
+	if _, err := Parse("prod-logs", opts...); err != nil {
+		t.Errorf("Parse(%q) error = %v", "prod-logs", err)
+	}
+	if _, err := Parse("prod-logs/x", opts...); !errors.Is(err, ErrAmbiguousInput) {
+		t.Errorf("Parse(%q) error = %v, want ErrAmbiguousInput", "prod-logs/x", err)
+	}
+	client := &mockHTTPClient{region: "eu-west-1"}
+	if _, err := GetBucketRegion(context.Background(), "prod-logs/x", append(opts, WithHTTPClient(client))...); !errors.Is(err, ErrAmbiguousInput) {
+		t.Errorf("GetBucketRegion(%q) error = %v, want ErrAmbiguousInput", "prod-logs/x", err)
+	}
+}
+
+func TestGetBucketRegionParseModes(t *testing.T) {
+	ctx := context.Background()
+
+	client := &mockHTTPClient{region: "eu-west-1"}
+	region, err := GetBucketRegion(ctx, `"s3://my-bucket/key",`, WithParseMode(ParseLenient), WithHTTPClient(client))
+	if err != nil || region != "eu-west-1" {
+		t.Errorf("lenient GetBucketRegion() = %q, %v, want %q", region, err, "eu-west-1")
+	}
+
+	res, err := Lookup(ctx, "`s3://my-bucket/key`).", WithParseMode(ParseLenient), WithHTTPClient(client))
+	if err != nil || res.Prefix != "`" || res.Suffix != "`)." {
+		t.Errorf("lenient Lookup() = %+v, %v, want prefix %q and suffix %q", res, err, "`", "`).")
+	}
+
+	client = &mockHTTPClient{region: "eu-west-1"}
+	_, err = GetBucketRegion(ctx, "my-bucket/path", WithParseMode(ParseStrict), WithHTTPClient(client))
+	if !errors.Is(err, ErrAmbiguousInput) {
+		t.Errorf("strict GetBucketRegion() error = %v, want ErrAmbiguousInput", err)
+	}
+	if client.called {
+		t.Error("strict GetBucketRegion() sent a request for an ambiguous input")
+	}
+
+	// Errors keep the input as given, decorations included
+	_, err = GetBucketRegion(ctx, "`Not_A_Bucket`", WithParseMode(ParseLenient), WithHTTPClient(client))
+	var e *Error
+	if !errors.As(err, &e) || e.Input != "`Not_A_Bucket`" || !errors.Is(err, ErrInvalidBucketName) {
+		t.Errorf("lenient GetBucketRegion() error = %v, want ErrInvalidBucketName for the original input", err)
+	}
+}
@@ -0,0 +1,86 @@
+package s3region
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Object is the metadata of an object returned by LookupObject. Fields the
+// anonymous HEAD response does not carry are left empty.
+type Object struct {
+	*Result            // Region lookup of the object's bucket, with its Key
+	Size         int64 // Content-Length, -1 if not reported
+	ContentType  string
+	ETag         string    // ETag header, including its quotes
+	LastModified time.Time // Zero if not reported
+	StorageClass string    // x-amz-storage-class, not reported for STANDARD
+	VersionID    string    // x-amz-version-id, for versioned buckets
+
+	ObjectEndpoint   string // URL of the object HEAD request
+	ObjectStatusCode int    // HTTP status of the object HEAD request
+}
+
+// LookupObject accepts any S3 identifier format accepted by GetBucketRegion
+// that names an object, such as s3://bucket/key or an object URL. It looks
+// up the bucket's region and sends an anonymous HEAD request for the object
+// to the regional endpoint.
+//
+// An input without a key fails with ErrNotObject once the region lookup has
+// resolved it, as the key of a custom domain URL is only known then. A missing object fails with
+// ErrObjectNotFound, an object that cannot be read anonymously with
+// ErrAccessDenied.
+func LookupObject(ctx context.Context, input string, opts ...Option) (*Object, error) {
+	const op = "LookupObject"
+
+	// The key is only known once a custom domain is resolved
+	res, err := lookupInput(ctx, op, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	if res.Bucket == "" {
+		return nil, newError(op, "", input, ErrNoBucket)
+	}
+	if res.Key == "" || strings.HasSuffix(res.Key, "/") {
+		return nil, newError(op, res.Bucket, input, ErrNotObject)
+	}
+
+	start := time.Now()
+	url := endpointURL(res.Bucket, res.Key, res.Region)
+	resp, err := send(ctx, newConfig(opts), http.MethodHead, url)
+	if err != nil {
+		err = newError(op, res.Bucket, input, err)
+		err.(*Error).Elapsed = res.Elapsed + time.Since(start)
+		return nil, err
+	}
+	if resp.status != http.StatusOK {
+		class := statusErrorClass(resp.status)
+		if resp.status == http.StatusNotFound {
+			class = ErrObjectNotFound
+		}
+		err = newError(op, res.Bucket, input, resp.error(class))
+		err.(*Error).Elapsed = res.Elapsed + time.Since(start)
+		return nil, err
+	}
+
+	obj := &Object{
+		Result:           res,
+		Size:             -1,
+		ContentType:      resp.header.Get("Content-Type"),
+		ETag:             resp.header.Get("ETag"),
+		StorageClass:     resp.header.Get("x-amz-storage-class"),
+		VersionID:        resp.header.Get("x-amz-version-id"),
+		ObjectEndpoint:   url,
+		ObjectStatusCode: resp.status,
+	}
+	if size, err := strconv.ParseInt(resp.header.Get("Content-Length"), 10, 64); err == nil {
+		obj.Size = size
+	}
+	if t, err := http.ParseTime(resp.header.Get("Last-Modified")); err == nil {
+		obj.LastModified = t
+	}
+	res.Elapsed += time.Since(start)
+	return obj, nil
+}
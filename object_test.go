@@ -0,0 +1,126 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLookupObject(t *testing.T) {
+	region := map[string]string{"x-amz-bucket-region": "eu-west-1"}
+	objectURL := "HEAD https://my-bucket.s3.eu-west-1.amazonaws.com/data/file.csv"
+
+	client := &routeClient{routes: map[string]stubResponse{
+		headURL: {403, region, ""},
+		objectURL: {200, map[string]string{
+			"Content-Length":      "1048576",
+			"Content-Type":        "text/csv",
+			"ETag":                `"9b2cf535f27731c974343645a3985328"`,
+			"Last-Modified":       "Wed, 14 Oct 2026 09:30:00 GMT",
+			"x-amz-storage-class": "STANDARD_IA",
+			"x-amz-version-id":    "3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY",
+		}, ""},
+	}}
+	obj, err := LookupObject(context.Background(), "s3://my-bucket/data/file.csv", WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("LookupObject() error = %v", err)
+	}
+	want := Object{
+		Size:             1048576,
+		ContentType:      "text/csv",
+		ETag:             `"9b2cf535f27731c974343645a3985328"`,
+		LastModified:     time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC),
+		StorageClass:     "STANDARD_IA",
+		VersionID:        "3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY",
+		ObjectEndpoint:   "https://my-bucket.s3.eu-west-1.amazonaws.com/data/file.csv",
+		ObjectStatusCode: 200,
+	}
+	got := *obj
+	got.Result = nil
+	if got != want {
+		t.Errorf("LookupObject() = %+v\nwant %+v", got, want)
+	}
+	if obj.Region != "eu-west-1" || obj.Bucket != "my-bucket" || obj.Key != "data/file.csv" {
+		t.Errorf("LookupObject() = %q, %q in %q, want my-bucket, data/file.csv in eu-west-1", obj.Bucket, obj.Key, obj.Region)
+	}
+}
+
+func TestLookupObjectErrors(t *testing.T) {
+	region := map[string]string{"x-amz-bucket-region": "eu-west-1"}
+	objectURL := "HEAD https://my-bucket.s3.eu-west-1.amazonaws.com/data/file.csv"
+
+	tests := []struct {
+		name       string
+		input      string
+		routes     map[string]stubResponse
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name:    "no key",
+			input:   "s3://my-bucket/",
+			routes:  map[string]stubResponse{headURL: {200, region, ""}},
+			wantErr: ErrNotObject,
+		},
+		{
+			name:    "access point",
+			input:   "https://my-ap-123456789012.s3-accesspoint.us-west-2.amazonaws.com/key",
+			wantErr: ErrNoBucket,
+		},
+		{
+			name:       "object not found",
+			input:      "https://my-bucket.s3.amazonaws.com/data/file.csv",
+			routes:     map[string]stubResponse{headURL: {200, region, ""}, objectURL: {404, nil, ""}},
+			wantErr:    ErrObjectNotFound,
+			wantStatus: 404,
+		},
+		{
+			name:       "access denied",
+			input:      "s3://my-bucket/data/file.csv",
+			routes:     map[string]stubResponse{headURL: {403, region, ""}, objectURL: {403, nil, ""}},
+			wantErr:    ErrAccessDenied,
+			wantStatus: 403,
+		},
+		{
+			name:       "bucket not found",
+			input:      "s3://my-bucket/data/file.csv",
+			routes:     map[string]stubResponse{headURL: {404, nil, ""}},
+			wantErr:    ErrBucketNotFound,
+			wantStatus: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &routeClient{routes: tt.routes}
+			_, err := LookupObject(context.Background(), tt.input, WithHTTPClient(client))
+			var e *Error
+			if !errors.As(err, &e) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LookupObject() error = %v, want %v", err, tt.wantErr)
+			}
+			if e.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", e.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLookupObjectCustomDomain(t *testing.T) {
+	resolver := &fakeResolver{cnames: map[string]string{
+		"assets.example.com": "assets.example.com.s3.amazonaws.com.",
+	}}
+	client := &routeClient{routes: map[string]stubResponse{
+		"HEAD https://assets.example.com.s3.amazonaws.com":                        {200, map[string]string{"x-amz-bucket-region": "eu-west-1"}, ""},
+		"HEAD https://s3.eu-west-1.amazonaws.com/assets.example.com/img/logo.png": {200, map[string]string{"Content-Type": "image/png"}, ""},
+	}}
+	obj, err := LookupObject(context.Background(), "https://assets.example.com/img/logo.png",
+		WithHTTPClient(client), WithResolver(resolver), WithCNAMELookup(true))
+	if err != nil {
+		t.Fatalf("LookupObject() error = %v; requests = %q", err, client.requests)
+	}
+	if obj.Bucket != "assets.example.com" || obj.Key != "img/logo.png" || obj.ContentType != "image/png" {
+		t.Errorf("LookupObject() = %q, %q, %q, want assets.example.com, img/logo.png, image/png",
+			obj.Bucket, obj.Key, obj.ContentType)
+	}
+}
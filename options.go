@@ -1,6 +1,10 @@
 package s3region
 
-import "net/http"
+import (
+	"context"
+	"net"
+	"net/http"
+)
 
 // HTTPClient interface allows custom HTTP client implementations.
 // The standard *http.Client implements this interface.
@@ -8,18 +12,163 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// Resolver interface allows custom DNS resolver implementations.
+// The standard *net.Resolver implements this interface.
+type Resolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
 // config holds configuration options for S3 region lookup.
 type config struct {
-	httpClient HTTPClient
+	httpClient  HTTPClient
+	resolver    Resolver
+	aliases     map[string]string
+	cache       *Cache
+	fallbacks   []Source
+	retry       RetryPolicy
+	strategy    Strategy
+	parseMode   ParseMode
+	regionHints bool
+	followCNAME bool
 }
 
+// Strategy selects how GetBucketRegionByName discovers a bucket's region.
+type Strategy int
+
+const (
+	StrategyHTTP        Strategy = iota // HEAD request only (default)
+	StrategyDNS                         // DNS answers only, no HTTP request
+	StrategyHTTPThenDNS                 // HEAD request, falling back to DNS if it fails
+	StrategyDNSThenHTTP                 // DNS answers, falling back to a HEAD request
+)
+
+// ParseMode selects how GetBucketRegion and Parse treat inputs that are not
+// a clean identifier.
+type ParseMode int
+
+const (
+	ParseDefault ParseMode = iota // Input is parsed as given (default)
+	ParseStrict                   // Ambiguous inputs are rejected with ErrAmbiguousInput
+	ParseLenient                  // Surrounding whitespace, punctuation and markdown are stripped
+)
+
 // Option is a function that configures the internal config.
 type Option func(*config)
 
+// newConfig returns the default config with opts applied.
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		httpClient: http.DefaultClient,
+		resolver:   net.DefaultResolver,
+		fallbacks:  defaultFallbacks,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	cfg.httpClient = withoutRedirects(cfg.httpClient)
+	return cfg
+}
+
 // WithHTTPClient sets a custom HTTP client for S3 requests.
-// If not provided, http.DefaultClient is used.
+// If not provided, http.DefaultClient is used. Lookups send their requests
+// through a copy of an *http.Client that does not follow redirects, so the
+// region is read from the redirect response; other implementations should
+// not follow redirects either.
 func WithHTTPClient(client HTTPClient) Option {
 	return func(c *config) {
 		c.httpClient = client
 	}
 }
+
+// WithRegionHints makes lookups trust a region hint carried by the input,
+// such as the region in a regional endpoint hostname, instead of performing
+// a HEAD request. Disabled by default because a hint can name a different
+// region than the one the bucket lives in.
+func WithRegionHints(enabled bool) Option {
+	return func(c *config) {
+		c.regionHints = enabled
+	}
+}
+
+// WithStrategy selects how a bucket name is resolved to a region.
+// If not provided, StrategyHTTP is used.
+func WithStrategy(strategy Strategy) Option {
+	return func(c *config) {
+		c.strategy = strategy
+	}
+}
+
+// WithFallbacks sets the sources tried, in order, when the HEAD response
+// lacks the x-amz-bucket-region header, as happens behind some proxies and
+// with S3-compatible stores. SourceLocationHeader and SourceRedirectEndpoint
+// read redirect responses, and SourceLocationConstraint sends an anonymous
+// GET /?location request. Calling WithFallbacks with no sources disables
+// the fallbacks.
+// If not provided, SourceLocationHeader, SourceRedirectEndpoint and
+// SourceLocationConstraint are tried in that order.
+func WithFallbacks(sources ...Source) Option {
+	return func(c *config) {
+		c.fallbacks = sources
+	}
+}
+
+// WithRetry sets the policy for retrying requests that fail with a
+// transient error. Retry-After headers are honored, and the context deadline
+// always ends the retries. The attempts made are recorded in Error.Attempts.
+// If not provided, requests are not retried.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = policy
+	}
+}
+
+// WithResolver sets a custom DNS resolver for CNAME lookups and DNS-based
+// region inference.
+// If not provided, net.DefaultResolver is used.
+func WithResolver(resolver Resolver) Option {
+	return func(c *config) {
+		c.resolver = resolver
+	}
+}
+
+// WithCNAMELookup makes HTTP/HTTPS URL lookups follow the CNAME chain of a
+// host that is not an S3 endpoint, such as assets.example.com, to discover
+// the bucket it serves. Disabled by default.
+func WithCNAMELookup(enabled bool) Option {
+	return func(c *config) {
+		c.followCNAME = enabled
+	}
+}
+
+// WithCache makes lookups by bucket name remember their results in cache and
+// answer later lookups of the same bucket from it, with SourceCache.
+func WithCache(cache *Cache) Option {
+	return func(c *config) {
+		c.cache = cache
+	}
+}
+
+// WithAliases sets named aliases for bucket identifiers, such as
+// "prod-logs" for "acme-prod-logs-7f3a2c-us-east-1". GetBucketRegion and
+// Parse expand an input that equals an alias, or an alias followed by
+// "/path", before parsing it; aliases win over bucket names of the same name.
+// Errors keep the alias as their Input.
+func WithAliases(aliases map[string]string) Option {
+	return func(c *config) {
+		c.aliases = aliases
+	}
+}
+
+// WithParseMode selects how GetBucketRegion and Parse treat inputs that are
+// not a clean identifier. ParseStrict rejects plain bucket names followed by
+// a path, URLs whose host is not an S3 endpoint, and inputs containing
+// whitespace, quotes, backticks or angle brackets. ParseLenient strips
+// surrounding whitespace, quotes, brackets, trailing punctuation and markdown
+// link syntax before parsing, and Parse reports the removed text in
+// Reference.Prefix and Reference.Suffix.
+// If not provided, ParseDefault is used.
+func WithParseMode(mode ParseMode) Option {
+	return func(c *config) {
+		c.parseMode = mode
+	}
+}
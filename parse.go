@@ -0,0 +1,516 @@
+package s3region
+
+import (
+	neturl "net/url"
+	"strings"
+)
+
+// Reference is an S3 bucket reference parsed from user input without any
+// network access.
+type Reference struct {
+	Input         string   // Original input provided by user
+	Alias         string   // Alias the input was expanded from, if any
+	Scheme        string   // Lowercase URI scheme (s3, s3a, s3n, http or https), empty for names and ARNs
+	Bucket        string   // Bucket name
+	Key           string   // Object key or prefix, if any, percent-decoded for URLs
+	Partition     string   // AWS partition (aws, aws-cn or aws-us-gov) if the input determines it
+	Region        string   // Region pinned by the endpoint itself (e.g. a VPC endpoint)
+	RegionHint    string   // Region suggested by the input, which may differ from the bucket's
+	VPCEndpointID string   // Interface VPC endpoint ID for PrivateLink hostnames
+	AccessPoint   string   // Access point name for access point hostnames, Bucket is then empty
+	AccountID     string   // AWS account ID owning the access point
+	Zone          string   // Availability Zone ID of an S3 Express directory bucket (e.g. usw2-az1)
+	Presign       *Presign // Presigned query parameters, nil if the URL is not presigned
+	Prefix        string   // Text stripped before the identifier in ParseLenient mode
+	Suffix        string   // Text stripped after the identifier in ParseLenient mode
+}
+
+// valid reports whether the reference names a valid bucket or access point.
+func (r *Reference) valid() bool {
+	if r.AccessPoint != "" {
+		return r.Bucket == "" && isValidBucketName(r.AccessPoint)
+	}
+	return isValidBucketName(r.Bucket)
+}
+
+// dnsSuffixes lists the DNS suffixes under which S3 endpoints are served.
+var dnsSuffixes = []string{"amazonaws.com", "amazonaws.com.cn"}
+
+// s3Host is the result of parsing an S3 endpoint hostname.
+type s3Host struct {
+	bucket        string // Bucket name for virtual-hosted-style hosts, empty for path-style
+	region        string // Region pinned by the endpoint
+	regionHint    string // Region named by a regional endpoint
+	vpcEndpointID string
+	accessPoint   string
+	accountID     string
+	zone          string
+}
+
+// parseS3Host parses an S3 endpoint hostname. It reports false if the host
+// is not served under a known S3 DNS suffix or has no S3 service label.
+func parseS3Host(host string) (s3Host, bool) {
+	var h s3Host
+
+	if idx := strings.LastIndex(host, ":"); idx != -1 {
+		host = host[:idx]
+	}
+
+	rest, suffix := splitDNSSuffix(host)
+	if suffix == "" {
+		return h, false
+	}
+
+	// PrivateLink interface endpoints:
+	// [bucket.]bucket.vpce-1a2b3c4d-5e6f.s3.us-east-1.vpce.amazonaws.com
+	if strings.HasSuffix(rest, ".vpce") {
+		return parseVPCEndpointHost(strings.TrimSuffix(rest, ".vpce"))
+	}
+
+	labels := strings.Split(rest, ".")
+	if h, ok := parseAccessPointHost(labels); ok {
+		return h, true
+	}
+	if h, ok := parseExpressHost(labels); ok {
+		return h, true
+	}
+
+	// The service label is the right-most "s3" label, so buckets whose names
+	// contain an "s3" label are still split correctly.
+	svc := -1
+	for i := len(labels) - 1; i >= 0; i-- {
+		if labels[i] == "s3" || strings.HasPrefix(labels[i], "s3-") {
+			svc = i
+			break
+		}
+	}
+	if svc == -1 {
+		return h, false
+	}
+	h.bucket = strings.Join(labels[:svc], ".")
+
+	endpoint := labels[svc:]
+	switch {
+	case len(endpoint) > 1:
+		// Regional endpoints: s3.us-west-2, s3.dualstack.us-west-2,
+		// s3-fips.us-east-1 or the website endpoint s3-website.eu-central-1
+		if endpoint[1] == "dualstack" {
+			endpoint = endpoint[1:]
+		}
+		if len(endpoint) == 2 && isRegionLike(endpoint[1]) {
+			h.regionHint = endpoint[1]
+		}
+	case len(endpoint) == 1:
+		// Legacy dash-style endpoints: s3-us-west-2, s3-external-1 or
+		// the website endpoint s3-website-us-east-1
+		h.regionHint = legacyEndpointRegion(labels[svc])
+	}
+	return h, true
+}
+
+// legacyEndpointRegion returns the region of a legacy dash-style endpoint
+// label such as s3-eu-west-1, s3-fips-us-gov-west-1, s3-website-us-east-1 or
+// s3-external-1. It returns "" for other s3- labels such as s3-accelerate.
+func legacyEndpointRegion(label string) string {
+	rest := strings.TrimPrefix(label, "s3-")
+	if rest == "external-1" {
+		return "us-east-1"
+	}
+	rest = strings.TrimPrefix(rest, "fips-")
+	rest = strings.TrimPrefix(rest, "website-")
+	if isRegionLike(rest) {
+		return rest
+	}
+	return ""
+}
+
+// splitDNSSuffix splits host into the part in front of its S3 DNS suffix and
+// the suffix itself. It returns an empty suffix if host has none.
+func splitDNSSuffix(host string) (string, string) {
+	for _, suffix := range dnsSuffixes {
+		if strings.HasSuffix(host, "."+suffix) {
+			return strings.TrimSuffix(host, "."+suffix), suffix
+		}
+	}
+	return host, ""
+}
+
+// parseVPCEndpointHost parses the part of a PrivateLink hostname in front of
+// ".vpce.<dns-suffix>". The endpoint-type label "bucket" marks the host as an
+// S3 bucket endpoint; a bucket name in front of it selects virtual-hosted style.
+func parseVPCEndpointHost(rest string) (s3Host, bool) {
+	var h s3Host
+
+	labels := strings.Split(rest, ".")
+	n := len(labels)
+	if n < 3 || labels[n-2] != "s3" || !strings.HasPrefix(labels[n-3], "vpce-") || !isRegionLike(labels[n-1]) {
+		return h, false
+	}
+	h.region = labels[n-1]
+	h.vpcEndpointID = labels[n-3]
+
+	prefix := labels[:n-3]
+	if len(prefix) > 0 && prefix[len(prefix)-1] == "bucket" {
+		prefix = prefix[:len(prefix)-1]
+	}
+	h.bucket = strings.Join(prefix, ".")
+	return h, true
+}
+
+// parseAccessPointHost parses the labels of an access point hostname:
+// my-ap-123456789012.s3-accesspoint[-fips][.dualstack].us-west-2
+func parseAccessPointHost(labels []string) (s3Host, bool) {
+	var h s3Host
+
+	n := len(labels)
+	if n < 3 || (labels[1] != "s3-accesspoint" && labels[1] != "s3-accesspoint-fips") || !isRegionLike(labels[n-1]) {
+		return h, false
+	}
+	if n > 4 || (n == 4 && labels[2] != "dualstack") {
+		return h, false
+	}
+
+	idx := strings.LastIndex(labels[0], "-")
+	if idx == -1 || !isAccountID(labels[0][idx+1:]) {
+		return h, false
+	}
+	h.accessPoint = labels[0][:idx]
+	h.accountID = labels[0][idx+1:]
+	h.region = labels[n-1]
+	return h, true
+}
+
+// parseExpressHost parses the labels of an S3 Express One Zone hostname,
+// either a zonal endpoint (bucket--usw2-az1--x-s3.s3express-usw2-az1.us-west-2)
+// or the regional control endpoint used path-style (s3express-control.us-west-2).
+func parseExpressHost(labels []string) (s3Host, bool) {
+	var h s3Host
+
+	n := len(labels)
+	if n < 2 || !isRegionLike(labels[n-1]) {
+		return h, false
+	}
+
+	switch {
+	case n == 2 && labels[0] == "s3express-control":
+	case n == 3 && strings.HasPrefix(labels[1], "s3express-") && labels[1] != "s3express-control":
+		h.bucket = labels[0]
+		h.zone = strings.TrimPrefix(strings.TrimPrefix(labels[1], "s3express-"), "fips-")
+	default:
+		return h, false
+	}
+	h.region = labels[n-1]
+	return h, true
+}
+
+// directoryBucketZone returns the Availability Zone ID encoded in an S3
+// Express directory bucket name (bucket-base-name--usw2-az1--x-s3), or ""
+// if name is a general purpose bucket name.
+func directoryBucketZone(name string) string {
+	base, ok := strings.CutSuffix(name, "--x-s3")
+	if !ok {
+		return ""
+	}
+	idx := strings.LastIndex(base, "--")
+	if idx == -1 {
+		return ""
+	}
+	return base[idx+2:]
+}
+
+// isAccountID reports whether s is a 12-digit AWS account ID.
+func isAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isRegionLike reports whether s has the shape of an AWS region code,
+// such as us-west-2 or us-gov-east-1. The first part is always a two-letter
+// area code, which keeps labels like website-us-east-1 from matching.
+func isRegionLike(s string) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 || len(parts[0]) != 2 {
+		return false
+	}
+	for _, part := range parts[:len(parts)-1] {
+		if len(part) == 0 {
+			return false
+		}
+		for j := 0; j < len(part); j++ {
+			if part[j] < 'a' || part[j] > 'z' {
+				return false
+			}
+		}
+	}
+	last := parts[len(parts)-1]
+	if len(last) == 0 {
+		return false
+	}
+	for j := 0; j < len(last); j++ {
+		if last[j] < '0' || last[j] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseHTTPURL splits an HTTP/HTTPS URL into a Reference. The bucket name is
+// not validated.
+func parseHTTPURL(rawURL string) *Reference {
+	if ref, ok := parseConsoleURL(rawURL); ok {
+		return ref
+	}
+
+	ref := &Reference{Input: rawURL}
+
+	// Remove protocol
+	url := rawURL
+	if scheme, rest, ok := strings.Cut(rawURL, "://"); ok {
+		ref.Scheme = strings.ToLower(scheme)
+		url = rest
+	}
+
+	// Drop the fragment, then split off the query string
+	url, _, _ = strings.Cut(url, "#")
+	url, rawQuery, _ := strings.Cut(url, "?")
+
+	// Get the host part (before first /)
+	host := url
+	path := ""
+	if idx := strings.Index(url, "/"); idx != -1 {
+		host = url[:idx]
+		path = url[idx+1:]
+	}
+
+	h, ok := parseS3Host(host)
+	switch {
+	case ok && h.accessPoint != "":
+		// Access point URL (my-ap-123456789012.s3-accesspoint.us-west-2.amazonaws.com)
+		ref.Key = path
+	case ok && h.bucket != "":
+		// Virtual-hosted-style URL (bucket-name.s3.amazonaws.com)
+		ref.Bucket, ref.Key = h.bucket, path
+	case !ok && (strings.Contains(host, ".s3.") || strings.Contains(host, ".s3-")):
+		// S3-style host under an unknown domain, extract bucket name from host (before .s3)
+		ref.Bucket, ref.Key = host[:strings.Index(host, ".s3")], path
+	case !ok && path == "":
+		// If we couldn't parse it, treat the host as bucket name
+		ref.Bucket = host
+	default:
+		// Path-style URL (s3.amazonaws.com/bucket-name or s3.region.amazonaws.com/bucket-name)
+		// Extract bucket name from path (first segment)
+		ref.Bucket, ref.Key = splitBucketPath(path)
+	}
+	if key, err := neturl.PathUnescape(ref.Key); err == nil {
+		ref.Key = key
+	}
+	if ok {
+		region := h.region
+		if region == "" {
+			region = h.regionHint
+		}
+		_, suffix := splitDNSSuffix(host)
+		ref.Partition = partitionForHost(suffix, region)
+	}
+	ref.Region = h.region
+	ref.RegionHint = h.regionHint
+	ref.VPCEndpointID = h.vpcEndpointID
+	ref.AccessPoint = h.accessPoint
+	ref.AccountID = h.accountID
+	ref.Zone = h.zone
+	if ref.Zone == "" {
+		ref.Zone = directoryBucketZone(ref.Bucket)
+	}
+
+	// A presigned URL is signed for the bucket's region, which makes its
+	// credential scope a better hint than the hostname.
+	if p, err := parsePresign(rawQuery); err == nil {
+		ref.Presign = p
+		if p.Region != "" {
+			ref.RegionHint = p.Region
+		}
+	}
+	return ref
+}
+
+// inputFormat is the format of an S3 identifier as detected by detectFormat.
+type inputFormat int
+
+const (
+	formatName    inputFormat = iota // my-bucket or my-bucket/path
+	formatARN                        // arn:aws:s3:::my-bucket
+	formatS3URI                      // s3://my-bucket, s3a://my-bucket or s3n://my-bucket
+	formatHTTPURL                    // http:// or https:// URL
+)
+
+// detectFormat detects the format of an S3 identifier from its prefix.
+func detectFormat(input string) inputFormat {
+	if _, ok := arnPartition(input); ok {
+		return formatARN
+	}
+	if _, ok := s3URIScheme(input); ok {
+		return formatS3URI
+	}
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		return formatHTTPURL
+	}
+	return formatName
+}
+
+// parseInput parses any S3 identifier accepted by GetBucketRegion into a
+// Reference. The bucket name is not validated.
+func parseInput(input string) *Reference {
+	switch detectFormat(input) {
+	case formatARN:
+		return parseARN(input)
+	case formatS3URI:
+		return parseS3URI(input)
+	case formatHTTPURL:
+		return parseHTTPURL(input)
+	}
+	ref := &Reference{Input: input}
+	ref.Bucket, ref.Key = splitBucketPath(input)
+	return ref
+}
+
+// Parse detects the format of any S3 identifier accepted by GetBucketRegion
+// and parses it into a Reference without making any network request.
+// Aliases set with WithAliases are expanded first, then parsers registered
+// with RegisterScheme are tried. WithParseMode selects how ambiguous or
+// decorated inputs are treated; other options are ignored.
+func Parse(input string, opts ...Option) (*Reference, error) {
+	const op = "Parse"
+
+	cfg := newConfig(opts)
+	if cfg.parseMode == ParseLenient {
+		if clean, prefix, suffix := sanitize(input); clean != input {
+			ref, err := Parse(clean, opts...)
+			if err != nil {
+				return nil, newError(op, "", input, err)
+			}
+			ref.Input, ref.Prefix, ref.Suffix = input, prefix, suffix
+			return ref, nil
+		}
+	}
+
+	if target, alias, ok := cfg.resolveAlias(input); ok {
+		ref, err := Parse(target, withoutAliases(opts)...)
+		if err != nil {
+			return nil, newError(op, "", input, err)
+		}
+		ref.Input = input
+		ref.Alias = alias
+		return ref, nil
+	}
+
+	ref, ok, err := parseRegistered(input)
+	if err != nil {
+		return nil, newError(op, "", input, err)
+	}
+	if !ok {
+		if cfg.parseMode == ParseStrict {
+			if err := checkStrict(input, cfg); err != nil {
+				return nil, newError(op, "", input, err)
+			}
+		}
+		ref = parseInput(input)
+	}
+	if !ref.valid() {
+		return nil, newError(op, ref.Bucket, input, ErrInvalidBucketName)
+	}
+	return ref, nil
+}
+
+// arnPartition returns the partition of an S3 bucket or object ARN such as
+// arn:aws:s3:::bucket-name or arn:aws-cn:s3:::bucket-name. It reports false
+// for any other input.
+func arnPartition(input string) (string, bool) {
+	for _, p := range partitions {
+		if strings.HasPrefix(input, "arn:"+p.id+":s3:::") {
+			return p.id, true
+		}
+	}
+	return "", false
+}
+
+// parseARN splits an S3 ARN such as arn:aws:s3:::bucket-name/path into a
+// Reference. The bucket name is not validated.
+func parseARN(arn string) *Reference {
+	ref := &Reference{Input: arn}
+	ref.Partition, _ = arnPartition(arn)
+	ref.Bucket, ref.Key = splitBucketPath(strings.TrimPrefix(arn, "arn:"+ref.Partition+":s3:::"))
+	return ref
+}
+
+// s3URISchemes lists the URI schemes treated as S3 URIs. Hadoop and Spark
+// use s3a and s3n for the same buckets.
+var s3URISchemes = []string{"s3", "s3a", "s3n"}
+
+// s3URIScheme returns the lowercase scheme of an S3 URI such as s3://bucket,
+// S3A://bucket or s3n://bucket. It reports false for any other input.
+func s3URIScheme(input string) (string, bool) {
+	scheme, _, ok := strings.Cut(input, "://")
+	if !ok {
+		return "", false
+	}
+	scheme = strings.ToLower(scheme)
+	for _, s := range s3URISchemes {
+		if scheme == s {
+			return scheme, true
+		}
+	}
+	return "", false
+}
+
+// parseS3URI splits an S3 URI into a Reference. The bucket name is not validated.
+func parseS3URI(uri string) *Reference {
+	ref := &Reference{Input: uri}
+	rest := uri
+	if scheme, ok := s3URIScheme(uri); ok {
+		ref.Scheme = scheme
+		rest = uri[len(scheme)+len("://"):]
+	}
+	ref.Bucket, ref.Key = splitBucketPath(rest)
+	return ref
+}
+
+// ParseS3URI parses an S3 URI (s3://, s3a:// or s3n://, case-insensitively)
+// into a Reference without making any network request.
+func ParseS3URI(uri string) (*Reference, error) {
+	const op = "ParseS3URI"
+
+	ref := parseS3URI(uri)
+	if !isValidBucketName(ref.Bucket) {
+		return nil, newError(op, ref.Bucket, uri, ErrInvalidBucketName)
+	}
+	return ref, nil
+}
+
+// splitBucketPath splits "bucket/path/to/object" into bucket and key.
+func splitBucketPath(path string) (bucket, key string) {
+	if idx := strings.Index(path, "/"); idx != -1 {
+		return path[:idx], path[idx+1:]
+	}
+	return path, ""
+}
+
+// ParseHTTPURL parses an HTTP/HTTPS S3 URL into a Reference without making
+// any network request. Supports virtual-hosted-style, path-style, regional
+// PrivateLink interface endpoint, access point and S3 Express URLs as well
+// as AWS console links.
+func ParseHTTPURL(url string) (*Reference, error) {
+	const op = "ParseHTTPURL"
+
+	ref := parseHTTPURL(url)
+	if !ref.valid() {
+		return nil, newError(op, ref.Bucket, url, ErrInvalidBucketName)
+	}
+	return ref, nil
+}
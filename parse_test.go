@@ -0,0 +1,319 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseHTTPURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantBucket string
+		wantKey    string
+		wantRegion string
+		wantHint   string
+		wantVPCE   string
+		wantAP     string
+		wantAcct   string
+		wantZone   string
+	}{
+		{
+			name:       "virtual-hosted global",
+			input:      "https://my-bucket.s3.amazonaws.com/path/to/object",
+			wantBucket: "my-bucket",
+			wantKey:    "path/to/object",
+		},
+		{
+			name:       "virtual-hosted regional",
+			input:      "https://my-bucket.s3.us-west-2.amazonaws.com/path",
+			wantBucket: "my-bucket",
+			wantKey:    "path",
+			wantHint:   "us-west-2",
+		},
+		{
+			name:       "virtual-hosted dualstack",
+			input:      "https://my-bucket.s3.dualstack.eu-west-1.amazonaws.com/key",
+			wantBucket: "my-bucket",
+			wantKey:    "key",
+			wantHint:   "eu-west-1",
+		},
+		{
+			name:       "path-style regional with query",
+			input:      "https://s3.us-west-2.amazonaws.com/my-bucket/path/to/object?versionId=1",
+			wantBucket: "my-bucket",
+			wantKey:    "path/to/object",
+			wantHint:   "us-west-2",
+		},
+		{
+			name:       "legacy dash-style path-style",
+			input:      "https://s3-eu-west-1.amazonaws.com/my-bucket/key",
+			wantBucket: "my-bucket",
+			wantKey:    "key",
+			wantHint:   "eu-west-1",
+		},
+		{
+			name:       "legacy dash-style virtual-hosted",
+			input:      "https://my-bucket.s3-ap-southeast-2.amazonaws.com/key",
+			wantBucket: "my-bucket",
+			wantKey:    "key",
+			wantHint:   "ap-southeast-2",
+		},
+		{
+			name:       "legacy govcloud fips",
+			input:      "https://s3-fips-us-gov-west-1.amazonaws.com/my-bucket",
+			wantBucket: "my-bucket",
+			wantHint:   "us-gov-west-1",
+		},
+		{
+			name:       "s3-external-1 path-style",
+			input:      "https://s3-external-1.amazonaws.com/my-bucket/key",
+			wantBucket: "my-bucket",
+			wantKey:    "key",
+			wantHint:   "us-east-1",
+		},
+		{
+			name:       "s3-external-1 virtual-hosted",
+			input:      "http://my-bucket.s3-external-1.amazonaws.com",
+			wantBucket: "my-bucket",
+			wantHint:   "us-east-1",
+		},
+		{
+			name:       "website endpoint dash-style",
+			input:      "http://my-bucket.s3-website-us-east-1.amazonaws.com/index.html",
+			wantBucket: "my-bucket",
+			wantKey:    "index.html",
+			wantHint:   "us-east-1",
+		},
+		{
+			name:       "website endpoint dot-style",
+			input:      "http://my-bucket.s3-website.eu-central-1.amazonaws.com",
+			wantBucket: "my-bucket",
+			wantHint:   "eu-central-1",
+		},
+		{
+			name:       "accelerate has no region",
+			input:      "https://my-bucket.s3-accelerate.amazonaws.com/key",
+			wantBucket: "my-bucket",
+			wantKey:    "key",
+		},
+		{
+			name:       "dotted bucket with s3 label",
+			input:      "https://logs.s3.example.s3.amazonaws.com/key",
+			wantBucket: "logs.s3.example",
+			wantKey:    "key",
+		},
+		{
+			name:       "privatelink virtual-hosted",
+			input:      "https://my-bucket.bucket.vpce-0a1b2c-3d4e.s3.us-east-1.vpce.amazonaws.com/key",
+			wantBucket: "my-bucket",
+			wantKey:    "key",
+			wantRegion: "us-east-1",
+			wantVPCE:   "vpce-0a1b2c-3d4e",
+		},
+		{
+			name:       "privatelink path-style",
+			input:      "https://bucket.vpce-0a1b2c-3d4e.s3.us-east-1.vpce.amazonaws.com/my-bucket/path/key",
+			wantBucket: "my-bucket",
+			wantKey:    "path/key",
+			wantRegion: "us-east-1",
+			wantVPCE:   "vpce-0a1b2c-3d4e",
+		},
+		{
+			name:       "privatelink without endpoint type label",
+			input:      "https://my-bucket.vpce-0a1b2c-3d4e.s3.eu-central-1.vpce.amazonaws.com/key",
+			wantBucket: "my-bucket",
+			wantKey:    "key",
+			wantRegion: "eu-central-1",
+			wantVPCE:   "vpce-0a1b2c-3d4e",
+		},
+		{
+			name:       "privatelink china",
+			input:      "https://bucket.vpce-0a1b2c-3d4e.s3.cn-north-1.vpce.amazonaws.com.cn/my-bucket",
+			wantBucket: "my-bucket",
+			wantRegion: "cn-north-1",
+			wantVPCE:   "vpce-0a1b2c-3d4e",
+		},
+		{
+			name:       "access point",
+			input:      "https://my-ap-123456789012.s3-accesspoint.us-west-2.amazonaws.com/path/key",
+			wantKey:    "path/key",
+			wantRegion: "us-west-2",
+			wantAP:     "my-ap",
+			wantAcct:   "123456789012",
+		},
+		{
+			name:       "access point dualstack",
+			input:      "https://reports-111122223333.s3-accesspoint.dualstack.eu-west-1.amazonaws.com",
+			wantRegion: "eu-west-1",
+			wantAP:     "reports",
+			wantAcct:   "111122223333",
+		},
+		{
+			name:       "s3 express zonal endpoint",
+			input:      "https://bucket--usw2-az1--x-s3.s3express-usw2-az1.us-west-2.amazonaws.com/key",
+			wantBucket: "bucket--usw2-az1--x-s3",
+			wantKey:    "key",
+			wantRegion: "us-west-2",
+			wantZone:   "usw2-az1",
+		},
+		{
+			name:       "s3 express control endpoint",
+			input:      "https://s3express-control.us-east-1.amazonaws.com/data--use1-az4--x-s3",
+			wantBucket: "data--use1-az4--x-s3",
+			wantRegion: "us-east-1",
+			wantZone:   "use1-az4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseHTTPURL(tt.input)
+			if err != nil {
+				t.Fatalf("ParseHTTPURL(%q) error = %v", tt.input, err)
+			}
+			if ref.Bucket != tt.wantBucket {
+				t.Errorf("Bucket = %q, want %q", ref.Bucket, tt.wantBucket)
+			}
+			if ref.Key != tt.wantKey {
+				t.Errorf("Key = %q, want %q", ref.Key, tt.wantKey)
+			}
+			if ref.Region != tt.wantRegion {
+				t.Errorf("Region = %q, want %q", ref.Region, tt.wantRegion)
+			}
+			if ref.RegionHint != tt.wantHint {
+				t.Errorf("RegionHint = %q, want %q", ref.RegionHint, tt.wantHint)
+			}
+			if ref.VPCEndpointID != tt.wantVPCE {
+				t.Errorf("VPCEndpointID = %q, want %q", ref.VPCEndpointID, tt.wantVPCE)
+			}
+			if ref.AccessPoint != tt.wantAP {
+				t.Errorf("AccessPoint = %q, want %q", ref.AccessPoint, tt.wantAP)
+			}
+			if ref.AccountID != tt.wantAcct {
+				t.Errorf("AccountID = %q, want %q", ref.AccountID, tt.wantAcct)
+			}
+			if ref.Zone != tt.wantZone {
+				t.Errorf("Zone = %q, want %q", ref.Zone, tt.wantZone)
+			}
+		})
+	}
+}
+
+func TestParseHTTPURLInvalid(t *testing.T) {
+	_, err := ParseHTTPURL("https://bucket.vpce-0a1b2c-3d4e.s3.us-east-1.vpce.amazonaws.com")
+	if !errors.Is(err, ErrInvalidBucketName) {
+		t.Errorf("ParseHTTPURL() error = %v, want %v", err, ErrInvalidBucketName)
+	}
+}
+
+func TestGetBucketRegionFromHTTPURLOffline(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		opts       []Option
+		wantRegion string
+		wantCalled bool
+	}{
+		{
+			name:       "privatelink region from host",
+			input:      "https://my-bucket.bucket.vpce-0a1b2c-3d4e.s3.us-east-1.vpce.amazonaws.com/key",
+			wantRegion: "us-east-1",
+		},
+		{
+			name:       "access point region from host",
+			input:      "https://my-ap-123456789012.s3-accesspoint.ap-northeast-1.amazonaws.com/key",
+			wantRegion: "ap-northeast-1",
+		},
+		{
+			name:       "s3 express region from host",
+			input:      "https://bucket--usw2-az1--x-s3.s3express-usw2-az1.us-west-2.amazonaws.com/key",
+			wantRegion: "us-west-2",
+		},
+		{
+			name:       "regional hint ignored by default",
+			input:      "https://my-bucket.s3.eu-west-1.amazonaws.com/key",
+			wantRegion: "us-west-1",
+			wantCalled: true,
+		},
+		{
+			name:       "regional hint trusted",
+			input:      "https://my-bucket.s3.eu-west-1.amazonaws.com/key",
+			opts:       []Option{WithRegionHints(true)},
+			wantRegion: "eu-west-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockHTTPClient{region: "us-west-1"}
+			opts := append([]Option{WithHTTPClient(client)}, tt.opts...)
+
+			region, err := GetBucketRegion(context.Background(), tt.input, opts...)
+			if err != nil {
+				t.Fatalf("GetBucketRegion() error = %v", err)
+			}
+			if region != tt.wantRegion {
+				t.Errorf("GetBucketRegion() = %q, want %q", region, tt.wantRegion)
+			}
+			if client.called != tt.wantCalled {
+				t.Errorf("HTTP client called = %v, want %v", client.called, tt.wantCalled)
+			}
+		})
+	}
+}
+
+func TestParseS3URI(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantScheme string
+		wantBucket string
+		wantKey    string
+	}{
+		{"s3://my-bucket", "s3", "my-bucket", ""},
+		{"s3://my-bucket/path/to/object", "s3", "my-bucket", "path/to/object"},
+		{"s3a://my-bucket/warehouse/table", "s3a", "my-bucket", "warehouse/table"},
+		{"s3n://my-bucket/path", "s3n", "my-bucket", "path"},
+		{"S3A://my-bucket/path", "s3a", "my-bucket", "path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			ref, err := ParseS3URI(tt.input)
+			if err != nil {
+				t.Fatalf("ParseS3URI(%q) error = %v", tt.input, err)
+			}
+			if ref.Scheme != tt.wantScheme {
+				t.Errorf("Scheme = %q, want %q", ref.Scheme, tt.wantScheme)
+			}
+			if ref.Bucket != tt.wantBucket {
+				t.Errorf("Bucket = %q, want %q", ref.Bucket, tt.wantBucket)
+			}
+			if ref.Key != tt.wantKey {
+				t.Errorf("Key = %q, want %q", ref.Key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestGetBucketRegionHadoopSchemes(t *testing.T) {
+	for _, input := range []string{"s3a://my-bucket/path", "s3n://my-bucket/path", "S3N://my-bucket"} {
+		t.Run(input, func(t *testing.T) {
+			client := &mockHTTPClient{region: "eu-west-1"}
+			region, err := GetBucketRegion(context.Background(), input, WithHTTPClient(client))
+			if err != nil {
+				t.Fatalf("GetBucketRegion(%q) error = %v", input, err)
+			}
+			if region != "eu-west-1" {
+				t.Errorf("GetBucketRegion(%q) = %q, want %q", input, region, "eu-west-1")
+			}
+		})
+	}
+
+	_, err := GetBucketRegion(context.Background(), "s3a://MY-BUCKET")
+	var e *Error
+	if !errors.As(err, &e) || e.Op != "GetBucketRegionFromS3URI" {
+		t.Errorf("GetBucketRegion(s3a://MY-BUCKET) error = %v, want GetBucketRegionFromS3URI error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package s3region
+
+import "strings"
+
+// partition describes an AWS partition: a group of regions sharing DNS
+// suffix, ARN prefix and console domain.
+type partition struct {
+	id            string // ARN partition, e.g. "aws-cn"
+	dnsSuffix     string // S3 endpoint DNS suffix
+	consoleDomain string // AWS Management Console domain
+}
+
+// partitions lists the supported AWS partitions. The first is the default.
+var partitions = []partition{
+	{id: "aws", dnsSuffix: "amazonaws.com", consoleDomain: "console.aws.amazon.com"},
+	{id: "aws-cn", dnsSuffix: "amazonaws.com.cn", consoleDomain: "console.amazonaws.cn"},
+	{id: "aws-us-gov", dnsSuffix: "amazonaws.com", consoleDomain: "console.amazonaws-us-gov.com"},
+}
+
+// lookupPartition returns the partition with the given ID.
+func lookupPartition(id string) (partition, bool) {
+	for _, p := range partitions {
+		if p.id == id {
+			return p, true
+		}
+	}
+	return partition{}, false
+}
+
+// partitionForRegion returns the ID of the partition a region belongs to.
+func partitionForRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+	return "aws"
+}
+
+// partitionForHost returns the ID of the partition serving an S3 endpoint
+// with the given DNS suffix and region, which may be empty.
+func partitionForHost(suffix, region string) string {
+	if suffix == "amazonaws.com.cn" {
+		return "aws-cn"
+	}
+	return partitionForRegion(region)
+}
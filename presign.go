@@ -0,0 +1,110 @@
+package s3region
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Presign describes the query-string authentication carried by a presigned URL.
+type Presign struct {
+	Version       int       // Signature version: 2 or 4
+	AccessKeyID   string    // Access key ID that signed the URL
+	Region        string    // Signing region from the credential scope (SigV4 only)
+	SigningDate   time.Time // Time the URL was signed (SigV4 only)
+	Expires       time.Time // Time after which S3 rejects the URL
+	SignedHeaders []string  // Headers covered by the signature (SigV4 only)
+}
+
+// Expired reports whether the URL is expired at the given time.
+func (p *Presign) Expired(now time.Time) bool {
+	return !now.Before(p.Expires)
+}
+
+// sigV4DateFormat is the layout of the X-Amz-Date query parameter.
+const sigV4DateFormat = "20060102T150405Z"
+
+// parsePresign parses presigned query parameters from a raw query string.
+// It returns ErrNotPresigned if the query carries no signature.
+func parsePresign(rawQuery string) (*Presign, error) {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedPresignedURL, err)
+	}
+
+	switch {
+	case query.Get("X-Amz-Algorithm") != "":
+		return parsePresignV4(query)
+	case query.Get("AWSAccessKeyId") != "" && query.Get("Signature") != "":
+		return parsePresignV2(query)
+	}
+	return nil, ErrNotPresigned
+}
+
+// parsePresignV4 parses SigV4 query parameters:
+// X-Amz-Credential=AKID/20261016/us-west-2/s3/aws4_request&X-Amz-Date=...&X-Amz-Expires=...
+func parsePresignV4(query url.Values) (*Presign, error) {
+	if alg := query.Get("X-Amz-Algorithm"); alg != "AWS4-HMAC-SHA256" {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrMalformedPresignedURL, alg)
+	}
+
+	scope := strings.Split(query.Get("X-Amz-Credential"), "/")
+	if len(scope) != 5 || scope[3] != "s3" || scope[4] != "aws4_request" {
+		return nil, fmt.Errorf("%w: invalid X-Amz-Credential %q", ErrMalformedPresignedURL, query.Get("X-Amz-Credential"))
+	}
+
+	signed, err := time.Parse(sigV4DateFormat, query.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid X-Amz-Date %q", ErrMalformedPresignedURL, query.Get("X-Amz-Date"))
+	}
+
+	seconds, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || seconds < 0 {
+		return nil, fmt.Errorf("%w: invalid X-Amz-Expires %q", ErrMalformedPresignedURL, query.Get("X-Amz-Expires"))
+	}
+
+	p := &Presign{
+		Version:     4,
+		AccessKeyID: scope[0],
+		Region:      scope[2],
+		SigningDate: signed,
+		Expires:     signed.Add(time.Duration(seconds) * time.Second),
+	}
+	if headers := query.Get("X-Amz-SignedHeaders"); headers != "" {
+		p.SignedHeaders = strings.Split(headers, ";")
+	}
+	return p, nil
+}
+
+// parsePresignV2 parses legacy SigV2 query parameters:
+// AWSAccessKeyId=AKID&Expires=1760000000&Signature=...
+func parsePresignV2(query url.Values) (*Presign, error) {
+	expires, err := strconv.ParseInt(query.Get("Expires"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid Expires %q", ErrMalformedPresignedURL, query.Get("Expires"))
+	}
+
+	return &Presign{
+		Version:     2,
+		AccessKeyID: query.Get("AWSAccessKeyId"),
+		Expires:     time.Unix(expires, 0).UTC(),
+	}, nil
+}
+
+// ParsePresignedURL parses the SigV4 or legacy SigV2 query parameters of a
+// presigned S3 URL without making any network request. It returns
+// ErrNotPresigned if the URL carries no signature.
+func ParsePresignedURL(url string) (*Presign, error) {
+	const op = "ParsePresignedURL"
+
+	_, rawQuery, _ := strings.Cut(url, "?")
+	rawQuery, _, _ = strings.Cut(rawQuery, "#")
+
+	p, err := parsePresign(rawQuery)
+	if err != nil {
+		return nil, newError(op, "", url, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,106 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePresignedURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *Presign
+		wantErr error
+	}{
+		{
+			name: "sigv4",
+			input: "https://my-bucket.s3.amazonaws.com/key?X-Amz-Algorithm=AWS4-HMAC-SHA256" +
+				"&X-Amz-Credential=AKIAEXAMPLE%2F20261016%2Fus-west-2%2Fs3%2Faws4_request" +
+				"&X-Amz-Date=20261016T120000Z&X-Amz-Expires=3600&X-Amz-SignedHeaders=host%3Bx-amz-meta-a" +
+				"&X-Amz-Signature=abc",
+			want: &Presign{
+				Version:       4,
+				AccessKeyID:   "AKIAEXAMPLE",
+				Region:        "us-west-2",
+				SigningDate:   time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+				Expires:       time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC),
+				SignedHeaders: []string{"host", "x-amz-meta-a"},
+			},
+		},
+		{
+			name:  "sigv2",
+			input: "https://my-bucket.s3.amazonaws.com/key?AWSAccessKeyId=AKIAEXAMPLE&Expires=1760616000&Signature=abc%3D",
+			want: &Presign{
+				Version:     2,
+				AccessKeyID: "AKIAEXAMPLE",
+				Expires:     time.Unix(1760616000, 0).UTC(),
+			},
+		},
+		{
+			name:    "not presigned",
+			input:   "https://my-bucket.s3.amazonaws.com/key?versionId=1",
+			wantErr: ErrNotPresigned,
+		},
+		{
+			name:    "bad credential scope",
+			input:   "https://my-bucket.s3.amazonaws.com/key?X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=AKIA",
+			wantErr: ErrMalformedPresignedURL,
+		},
+		{
+			name: "bad date",
+			input: "https://my-bucket.s3.amazonaws.com/key?X-Amz-Algorithm=AWS4-HMAC-SHA256" +
+				"&X-Amz-Credential=AKIA/20261016/us-west-2/s3/aws4_request&X-Amz-Date=yesterday&X-Amz-Expires=60",
+			wantErr: ErrMalformedPresignedURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePresignedURL(tt.input)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParsePresignedURL() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePresignedURL() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePresignedURL() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresignExpired(t *testing.T) {
+	p := &Presign{Expires: time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)}
+
+	if p.Expired(time.Date(2026, 10, 16, 12, 59, 59, 0, time.UTC)) {
+		t.Error("Expired() = true before expiry")
+	}
+	if !p.Expired(time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)) {
+		t.Error("Expired() = false at expiry")
+	}
+}
+
+func TestGetBucketRegionPresignedHint(t *testing.T) {
+	input := "https://my-bucket.s3.amazonaws.com/key?X-Amz-Algorithm=AWS4-HMAC-SHA256" +
+		"&X-Amz-Credential=AKIAEXAMPLE%2F20261016%2Fap-south-1%2Fs3%2Faws4_request" +
+		"&X-Amz-Date=20261016T120000Z&X-Amz-Expires=60&X-Amz-Signature=abc"
+
+	client := &mockHTTPClient{region: "us-west-1"}
+	region, err := GetBucketRegion(context.Background(), input, WithHTTPClient(client), WithRegionHints(true))
+	if err != nil {
+		t.Fatalf("GetBucketRegion() error = %v", err)
+	}
+	if region != "ap-south-1" {
+		t.Errorf("GetBucketRegion() = %q, want %q", region, "ap-south-1")
+	}
+	if client.called {
+		t.Error("HTTP client called for presigned URL with hints enabled")
+	}
+}
@@ -0,0 +1,56 @@
+package s3region
+
+import (
+	"fmt"
+	"net/http"
+	neturl "net/url"
+)
+
+// withoutRedirects returns a copy of client that does not follow redirects,
+// so that lookups read the region from the redirect response itself. Other
+// HTTPClient implementations are returned as is.
+func withoutRedirects(client HTTPClient) HTTPClient {
+	c, ok := client.(*http.Client)
+	if !ok || c == nil {
+		return client
+	}
+	copied := *c
+	copied.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &copied
+}
+
+// isRedirect reports whether status is an HTTP redirect status.
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// checkRedirect returns an error wrapping ErrRedirectNotAWS if a response
+// redirects to a host that is not an S3 endpoint. Relative redirects stay on
+// the host that was contacted and are accepted.
+func checkRedirect(status int, header http.Header) error {
+	if !isRedirect(status) {
+		return nil
+	}
+	location := header.Get("Location")
+	if location == "" {
+		return nil
+	}
+	u, err := neturl.Parse(location)
+	if err != nil {
+		return fmt.Errorf("%w: malformed Location %q", ErrRedirectNotAWS, location)
+	}
+	if u.Host == "" {
+		return nil
+	}
+	if _, ok := parseS3Host(u.Hostname()); !ok {
+		return fmt.Errorf("%w: %s", ErrRedirectNotAWS, u.Hostname())
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRedirectsNotFollowed(t *testing.T) {
+	var requests []string
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.URL.String())
+		resp := &http.Response{
+			StatusCode: http.StatusTemporaryRedirect,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}
+		resp.Header.Set("Location", "https://my-bucket.s3-eu-west-1.amazonaws.com/")
+		resp.Header.Set("x-amz-bucket-region", "eu-west-1")
+		return resp, nil
+	})}
+
+	res, err := LookupBucketRegion(context.Background(), "my-bucket", WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("LookupBucketRegion() error = %v", err)
+	}
+	if res.Region != "eu-west-1" || res.Source != SourceHeader {
+		t.Errorf("LookupBucketRegion() = %q from %v, want %q from header", res.Region, res.Source, "eu-west-1")
+	}
+	if len(requests) != 1 {
+		t.Errorf("requests = %q, want only the global endpoint", requests)
+	}
+	if client.CheckRedirect != nil {
+		t.Error("caller's client was modified")
+	}
+}
+
+func TestRedirectToNonAWSHost(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes map[string]stubResponse
+	}{
+		{
+			name: "HEAD",
+			routes: map[string]stubResponse{
+				headURL: {302, map[string]string{"Location": "https://proxy.example.com/login", "x-amz-bucket-region": "us-east-1"}, ""},
+			},
+		},
+		{
+			name: "GET ?location",
+			routes: map[string]stubResponse{
+				headURL:     {200, nil, ""},
+				locationURL: {307, map[string]string{"Location": "http://s3.example.net/my-bucket/?location"}, ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &routeClient{routes: tt.routes}
+			_, err := GetBucketRegionByName(context.Background(), "my-bucket", WithHTTPClient(client))
+			if !errors.Is(err, ErrRedirectNotAWS) {
+				t.Errorf("GetBucketRegionByName() error = %v, want ErrRedirectNotAWS", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package s3region
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ParserFunc parses an identifier in a custom scheme, such as
+// datalake://team/dataset, into a Reference naming the bucket it maps to.
+// Returning neither a reference nor an error is reported as
+// ErrInvalidBucketName.
+type ParserFunc func(input string) (*Reference, error)
+
+// schemeParser is a parser registered for an identifier prefix.
+type schemeParser struct {
+	prefix string
+	parse  ParserFunc
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []schemeParser // sorted by descending prefix length
+)
+
+// RegisterScheme registers a parser for identifiers starting with prefix,
+// such as "datalake://". GetBucketRegion and Parse try registered parsers
+// before the built-in formats, longest matching prefix first, and prefixes
+// match case-insensitively. Registering a prefix again replaces its parser.
+func RegisterScheme(prefix string, parser ParserFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i := range registry {
+		if strings.EqualFold(registry[i].prefix, prefix) {
+			registry[i].parse = parser
+			return
+		}
+	}
+	registry = append(registry, schemeParser{prefix: prefix, parse: parser})
+	sort.SliceStable(registry, func(i, j int) bool {
+		return len(registry[i].prefix) > len(registry[j].prefix)
+	})
+}
+
+// UnregisterScheme removes the parser registered for prefix, if any.
+func UnregisterScheme(prefix string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i := range registry {
+		if strings.EqualFold(registry[i].prefix, prefix) {
+			registry = append(registry[:i], registry[i+1:]...)
+			return
+		}
+	}
+}
+
+// lookupScheme returns the registered parser for input, if any.
+func lookupScheme(input string) (ParserFunc, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, s := range registry {
+		if len(input) >= len(s.prefix) && strings.EqualFold(input[:len(s.prefix)], s.prefix) {
+			return s.parse, true
+		}
+	}
+	return nil, false
+}
+
+// parseRegistered parses input with its registered parser. It reports false
+// if no parser is registered for input. The returned reference keeps input
+// as its Input.
+func parseRegistered(input string) (*Reference, bool, error) {
+	parse, ok := lookupScheme(input)
+	if !ok {
+		return nil, false, nil
+	}
+	ref, err := parse(input)
+	if err != nil {
+		return nil, true, err
+	}
+	if ref == nil {
+		return nil, true, ErrInvalidBucketName
+	}
+	ref.Input = input
+	return ref, true, nil
+}
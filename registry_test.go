@@ -0,0 +1,72 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRegisterScheme(t *testing.T) {
+	RegisterScheme("datalake://", func(input string) (*Reference, error) {
+		team, dataset, ok := strings.Cut(strings.TrimPrefix(strings.ToLower(input), "datalake://"), "/")
+		if !ok {
+			return nil, fmt.Errorf("missing dataset")
+		}
+		return &Reference{Bucket: "acme-datalake-" + team, Key: dataset}, nil
+	})
+	RegisterScheme("datalake://pinned/", func(input string) (*Reference, error) {
+		return &Reference{Bucket: "acme-pinned", Region: "eu-north-1"}, nil
+	})
+	defer UnregisterScheme("datalake://")
+	defer UnregisterScheme("datalake://pinned/")
+
+	ref, err := Parse("DataLake://analytics/events")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if ref.Bucket != "acme-datalake-analytics" || ref.Key != "events" || ref.Input != "DataLake://analytics/events" {
+		t.Errorf("Parse() = %+v", ref)
+	}
+
+	client := &mockHTTPClient{region: "us-east-2"}
+	region, err := GetBucketRegion(context.Background(), "datalake://analytics/events", WithHTTPClient(client))
+	if err != nil || region != "us-east-2" {
+		t.Errorf("GetBucketRegion() = %q, %v, want %q", region, err, "us-east-2")
+	}
+
+	// The longer prefix wins and its pinned region needs no request
+	client = &mockHTTPClient{region: "us-east-2"}
+	region, err = GetBucketRegion(context.Background(), "datalake://pinned/x", WithHTTPClient(client))
+	if err != nil || region != "eu-north-1" || client.called {
+		t.Errorf("GetBucketRegion() = %q, %v, called = %v, want %q offline", region, err, client.called, "eu-north-1")
+	}
+
+	_, err = GetBucketRegion(context.Background(), "datalake://analytics")
+	var e *Error
+	if !errors.As(err, &e) || e.Op != "GetBucketRegion" || e.Input != "datalake://analytics" {
+		t.Errorf("GetBucketRegion() error = %v, want GetBucketRegion error for the original input", err)
+	}
+
+	UnregisterScheme("datalake://")
+	if _, ok := lookupScheme("datalake://analytics/events"); ok {
+		t.Error("lookupScheme() found a parser after UnregisterScheme")
+	}
+}
+
+func TestRegisterSchemeNilReference(t *testing.T) {
+	RegisterScheme("nil://", func(string) (*Reference, error) { return nil, nil })
+	defer UnregisterScheme("nil://")
+
+	if _, err := Parse("nil://x"); !errors.Is(err, ErrInvalidBucketName) {
+		t.Errorf("Parse() error = %v, want %v", err, ErrInvalidBucketName)
+	}
+	client := &mockHTTPClient{region: "us-east-2"}
+	if _, err := Lookup(context.Background(), "nil://x", WithHTTPClient(client)); !errors.Is(err, ErrInvalidBucketName) {
+		t.Errorf("Lookup() error = %v, want %v", err, ErrInvalidBucketName)
+	}
+	if client.called {
+		t.Error("Lookup() sent a request for a nil reference")
+	}
+}
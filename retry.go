@@ -0,0 +1,189 @@
+package s3region
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how requests that fail with a transient error are
+// retried: 5xx and 429 responses, S3 throttling, connection resets and
+// timeouts. The context deadline is always respected.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts per request, including the first; below 1 means 1
+	BaseDelay   time.Duration // Delay before the first retry, doubled for each further retry
+	MaxDelay    time.Duration // Upper bound of a delay, 0 for none
+	Jitter      float64       // Fraction of each delay that is randomized, from 0 to 1
+}
+
+// DefaultRetryPolicy is a retry policy suitable for batch jobs.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.5,
+}
+
+// delay returns the delay before retry number n, counting from 1.
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < n && (p.MaxDelay == 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		jitter := min(p.Jitter, 1)
+		d = time.Duration(float64(d) * (1 - jitter + jitter*rand.Float64()))
+	}
+	return d
+}
+
+// response is an S3 response with its body read and closed.
+type response struct {
+	url      string
+	status   int
+	header   http.Header
+	body     []byte
+	attempts int // Number of attempts made to get the response
+}
+
+// requestID returns the x-amz-request-id header of the response.
+func (r *response) requestID() string {
+	return r.header.Get("x-amz-request-id")
+}
+
+// hostID returns the x-amz-id-2 header of the response.
+func (r *response) hostID() string {
+	return r.header.Get("x-amz-id-2")
+}
+
+// error wraps err with the metadata of the response.
+func (r *response) error(err error) *requestError {
+	return &requestError{
+		endpoint:  r.url,
+		status:    r.status,
+		requestID: r.requestID(),
+		hostID:    r.hostID(),
+		attempts:  r.attempts,
+		err:       err,
+	}
+}
+
+// sleep waits for d or until ctx is done. Tests replace it to avoid waiting.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// send sends an anonymous request to url, retrying transient failures with
+// the configured retry policy. At most maxBodySize bytes of the body are
+// read. A redirect to a host that is not an S3 endpoint is returned as an
+// error.
+func send(ctx context.Context, cfg *config, method, url string) (*response, error) {
+	policy := cfg.retry
+	for attempt := 1; ; attempt++ {
+		resp, err := sendOnce(ctx, cfg, method, url)
+
+		retry := attempt < policy.MaxAttempts
+		if err != nil {
+			retry = retry && ctx.Err() == nil && IsRetryable(err)
+		} else {
+			retry = retry && retryableStatus(resp.status)
+		}
+		var d time.Duration
+		if retry {
+			d = policy.delay(attempt)
+			if after, ok := retryAfter(resp); ok {
+				// A server asking for more than MaxDelay is not retried
+				d = max(d, after)
+				retry = policy.MaxDelay == 0 || after <= policy.MaxDelay
+			}
+			// Give up early rather than sleep past the deadline
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+				retry = false
+			}
+		}
+
+		if !retry {
+			if err != nil {
+				return nil, &requestError{endpoint: url, attempts: attempt, err: err}
+			}
+			resp.attempts = attempt
+			if err := checkRedirect(resp.status, resp.header); err != nil {
+				return nil, resp.error(err)
+			}
+			return resp, nil
+		}
+		if err := sleep(ctx, d); err != nil {
+			return nil, &requestError{endpoint: url, attempts: attempt, err: err}
+		}
+	}
+}
+
+// sendOnce sends a single anonymous request to url.
+func sendOnce(ctx context.Context, cfg *config, method, url string) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := cfg.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform %s request: %w", method, requestFailure(ctx, err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", method, requestFailure(ctx, err))
+	}
+	return &response{url: url, status: resp.StatusCode, header: resp.Header, body: body}, nil
+}
+
+// requestFailure classifies an error of a request sent with ctx. Once ctx
+// is done, the error is caused by the caller and returned as is rather than
+// reported as a timeout.
+func requestFailure(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	return classifyRequestError(err)
+}
+
+// retryableStatus reports whether a response with the given status is worth
+// retrying: throttling and server errors other than 501 Not Implemented.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests ||
+		status >= 500 && status != http.StatusNotImplemented
+}
+
+// retryAfter returns the delay requested by the Retry-After header of resp,
+// given in seconds or as an HTTP date.
+func retryAfter(resp *response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,205 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// sequenceClient serves the given outcomes in order, one per request: a
+// stubResponse or an error.
+type sequenceClient struct {
+	outcomes []any
+	calls    int
+}
+
+func (c *sequenceClient) Do(req *http.Request) (*http.Response, error) {
+	if c.calls >= len(c.outcomes) {
+		return nil, errors.New("unexpected request")
+	}
+	outcome := c.outcomes[c.calls]
+	c.calls++
+
+	if err, ok := outcome.(error); ok {
+		return nil, err
+	}
+	r := outcome.(stubResponse)
+	resp := &http.Response{
+		StatusCode: r.status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(r.body)),
+	}
+	for k, v := range r.header {
+		resp.Header.Set(k, v)
+	}
+	return resp, nil
+}
+
+// recordSleeps replaces sleep for the duration of the test and returns the
+// delays slept.
+func recordSleeps(t *testing.T) *[]time.Duration {
+	var delays []time.Duration
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = orig })
+	return &delays
+}
+
+func TestRetry(t *testing.T) {
+	ok := stubResponse{200, map[string]string{"x-amz-bucket-region": "us-west-2"}, ""}
+	slowDown := stubResponse{503, nil, ""}
+	reset := fmt.Errorf("read tcp: %w", syscall.ECONNRESET)
+	unknownCA := errors.New("x509: certificate signed by unknown authority")
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 150 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		outcomes     []any
+		policy       RetryPolicy
+		wantErr      error
+		wantCalls    int
+		wantAttempts int
+		wantDelays   []time.Duration
+	}{
+		{
+			name:       "no retries by default",
+			outcomes:   []any{slowDown, ok},
+			wantErr:    ErrThrottled,
+			wantCalls:  1,
+			wantDelays: nil,
+		},
+		{
+			name:       "server error then success",
+			outcomes:   []any{slowDown, reset, ok},
+			policy:     policy,
+			wantCalls:  3,
+			wantDelays: []time.Duration{100 * time.Millisecond, 150 * time.Millisecond},
+		},
+		{
+			name:         "attempts exhausted",
+			outcomes:     []any{slowDown, slowDown, slowDown},
+			policy:       policy,
+			wantErr:      ErrThrottled,
+			wantCalls:    3,
+			wantAttempts: 3,
+			wantDelays:   []time.Duration{100 * time.Millisecond, 150 * time.Millisecond},
+		},
+		{
+			name:       "Retry-After honored",
+			outcomes:   []any{stubResponse{503, map[string]string{"Retry-After": "2"}, ""}, ok},
+			policy:     RetryPolicy{MaxAttempts: 2, BaseDelay: 100 * time.Millisecond},
+			wantCalls:  2,
+			wantDelays: []time.Duration{2 * time.Second},
+		},
+		{
+			name:         "Retry-After beyond MaxDelay",
+			outcomes:     []any{stubResponse{503, map[string]string{"Retry-After": "60"}, ""}, ok},
+			policy:       policy,
+			wantErr:      ErrThrottled,
+			wantCalls:    1,
+			wantAttempts: 1,
+		},
+		{
+			name:         "not retryable",
+			outcomes:     []any{unknownCA, ok},
+			policy:       policy,
+			wantErr:      unknownCA,
+			wantCalls:    1,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := recordSleeps(t)
+			client := &sequenceClient{outcomes: tt.outcomes}
+
+			region, err := GetBucketRegionByName(context.Background(), "my-bucket",
+				WithHTTPClient(client), WithRetry(tt.policy))
+			if tt.wantErr == nil {
+				if err != nil || region != "us-west-2" {
+					t.Errorf("GetBucketRegionByName() = %q, %v, want %q", region, err, "us-west-2")
+				}
+			} else {
+				var e *Error
+				if !errors.As(err, &e) {
+					t.Fatalf("GetBucketRegionByName() error = %v, want *Error", err)
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetBucketRegionByName() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantAttempts != 0 && e.Attempts != tt.wantAttempts {
+					t.Errorf("Attempts = %d, want %d", e.Attempts, tt.wantAttempts)
+				}
+			}
+			if client.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", client.calls, tt.wantCalls)
+			}
+			if fmt.Sprint(*delays) != fmt.Sprint(tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", *delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestRetryRespectsDeadline(t *testing.T) {
+	recordSleeps(t)
+	client := &sequenceClient{outcomes: []any{stubResponse{503, nil, ""}, stubResponse{503, nil, ""}}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := GetBucketRegionByName(ctx, "my-bucket", WithHTTPClient(client),
+		WithRetry(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second}))
+
+	var e *Error
+	if !errors.As(err, &e) || e.Attempts != 1 || client.calls != 1 {
+		t.Errorf("error = %v, calls = %d, want one attempt before the deadline", err, client.calls)
+	}
+}
+
+func TestRetryClientTimeout(t *testing.T) {
+	recordSleeps(t)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("x-amz-bucket-region", "us-west-2")
+	}))
+	defer server.Close()
+
+	// Send every request to the test server, timing out after 20ms
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme, req.URL.Host = "http", server.Listener.Addr().String()
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	client := &http.Client{Transport: transport, Timeout: 20 * time.Millisecond}
+
+	region, err := GetBucketRegionByName(context.Background(), "my-bucket", WithHTTPClient(client),
+		WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	if err != nil || region != "us-west-2" || calls != 3 {
+		t.Errorf("GetBucketRegionByName() = %q, %v after %d calls, want %q after 3", region, err, calls, "us-west-2")
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.5}
+	for n := 1; n <= 6; n++ {
+		full := min(100*time.Millisecond<<(n-1), time.Second)
+		if d := p.delay(n); d < full/2 || d > full {
+			t.Errorf("delay(%d) = %v, want within [%v, %v]", n, d, full/2, full)
+		}
+	}
+}
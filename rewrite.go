@@ -0,0 +1,143 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Rewrite is an S3 URL replaced by RewriteURLs.
+type Rewrite struct {
+	Match       Match  // The URL as found in the original text
+	Replacement string // The regional virtual-hosted-style URL
+	Region      string // Region of the bucket
+}
+
+// RewriteURLs finds S3 URLs in text that use the global endpoint
+// (s3.amazonaws.com), path-style addressing or a legacy dash-style endpoint,
+// looks up their regions and rewrites them to the regional virtual-hosted
+// form https://<bucket>.s3.<region>.amazonaws.com/<key>, keeping the key,
+// query string and fragment. Presigned URLs are left alone because their
+// signature covers the host, as are URLs for buckets with dots in their name,
+// which cannot be served virtual-hosted-style over HTTPS.
+//
+// Buckets whose region cannot be looked up are left unchanged, and their
+// errors are joined into the returned error alongside the rewritten text.
+func RewriteURLs(ctx context.Context, text string, opts ...Option) (string, []Rewrite, error) {
+	var rewrites []Rewrite
+	var errs []error
+
+	regions := make(map[string]string)
+	failed := make(map[string]bool)
+
+	var b strings.Builder
+	last := 0
+	for _, m := range ExtractIdentifiers(text) {
+		if !isRewritable(m) {
+			continue
+		}
+
+		bucket := m.Reference.Bucket
+		region, ok := regions[bucket]
+		if !ok {
+			if failed[bucket] {
+				continue
+			}
+			var err error
+			region, err = GetBucketRegionByName(ctx, bucket, opts...)
+			if err != nil {
+				failed[bucket] = true
+				errs = append(errs, err)
+				continue
+			}
+			regions[bucket] = region
+		}
+
+		replacement := regionalURL(m, region)
+		if replacement == m.Text {
+			continue
+		}
+
+		b.WriteString(text[last:m.Offset])
+		b.WriteString(replacement)
+		last = m.Offset + len(m.Text)
+		rewrites = append(rewrites, Rewrite{Match: m, Replacement: replacement, Region: region})
+	}
+	b.WriteString(text[last:])
+
+	return b.String(), rewrites, errors.Join(errs...)
+}
+
+// isRewritable reports whether a match is an unsigned URL on a standard S3
+// endpoint whose bucket can be addressed virtual-hosted-style over HTTPS.
+func isRewritable(m Match) bool {
+	ref := m.Reference
+	if detectFormat(m.Text) != formatHTTPURL || ref.Presign != nil || ref.Bucket == "" {
+		return false
+	}
+	if strings.Contains(ref.Bucket, ".") {
+		return false
+	}
+	host, _ := splitURLHost(m.Text)
+	return isStandardEndpoint(host, ref.Bucket)
+}
+
+// isStandardEndpoint reports whether host is a plain S3 endpoint for bucket:
+// the global endpoint, a regional endpoint or a legacy dash-style endpoint,
+// addressed either virtual-hosted-style or path-style. PrivateLink, access
+// point, S3 Express, dual-stack, FIPS, accelerate and website endpoints are not.
+func isStandardEndpoint(host, bucket string) bool {
+	h, ok := parseS3Host(host)
+	if !ok || h.region != "" {
+		return false
+	}
+	if h.bucket != "" && h.bucket != bucket {
+		return false
+	}
+
+	_, suffix := splitDNSSuffix(host)
+	endpoint := strings.TrimSuffix(host, "."+suffix)
+	if h.bucket != "" {
+		endpoint = strings.TrimPrefix(endpoint, h.bucket+".")
+	}
+
+	switch {
+	case endpoint == "s3":
+		return true
+	case strings.HasPrefix(endpoint, "s3."):
+		return isRegionLike(strings.TrimPrefix(endpoint, "s3."))
+	case strings.HasPrefix(endpoint, "s3-"):
+		return !strings.Contains(endpoint, ".") && !strings.HasPrefix(endpoint, "s3-website") &&
+			!strings.HasPrefix(endpoint, "s3-fips") && legacyEndpointRegion(endpoint) != ""
+	}
+	return false
+}
+
+// regionalURL builds the regional virtual-hosted-style form of a matched URL,
+// keeping its scheme, key, query string and fragment.
+func regionalURL(m Match, region string) string {
+	ref := m.Reference
+	host, path := splitURLHost(m.Text)
+	_, suffix := splitDNSSuffix(host)
+
+	// Reuse the key as written rather than Reference.Key, which is decoded
+	key := path
+	if h, _ := parseS3Host(host); h.bucket == "" {
+		_, key = splitBucketPath(path)
+	}
+
+	// Everything after the key, i.e. the query string and fragment
+	rest := ""
+	if idx := strings.IndexAny(m.Text, "?#"); idx != -1 {
+		rest = m.Text[idx:]
+	}
+
+	url := ref.Scheme + "://" + ref.Bucket + ".s3." + region + "." + suffix + "/" + key + rest
+	if key == "" && rest == "" {
+		url = strings.TrimSuffix(url, "/")
+		if strings.HasSuffix(m.Text, "/") {
+			url += "/"
+		}
+	}
+	return url
+}
@@ -0,0 +1,124 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// bucketRegionClient is an HTTPClient answering HEAD requests with the
+// region of the bucket named in the request host, or 404 for unknown buckets.
+type bucketRegionClient struct {
+	regions map[string]string
+	calls   int
+}
+
+func (c *bucketRegionClient) Do(req *http.Request) (*http.Response, error) {
+	c.calls++
+	bucket := strings.TrimSuffix(req.URL.Hostname(), ".s3.amazonaws.com")
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+	}
+	region, ok := c.regions[bucket]
+	if !ok {
+		resp.StatusCode = http.StatusNotFound
+		return resp, nil
+	}
+	resp.Header.Set("x-amz-bucket-region", region)
+	return resp, nil
+}
+
+func TestRewriteURLs(t *testing.T) {
+	client := &bucketRegionClient{regions: map[string]string{
+		"west-bucket": "us-west-2",
+		"eu-bucket":   "eu-west-1",
+	}}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "global virtual-hosted",
+			input: "url: https://west-bucket.s3.amazonaws.com/path/to/key.txt\n",
+			want:  "url: https://west-bucket.s3.us-west-2.amazonaws.com/path/to/key.txt\n",
+		},
+		{
+			name:  "path-style with query and fragment",
+			input: "see https://s3.amazonaws.com/eu-bucket/key?versionId=3#top.",
+			want:  "see https://eu-bucket.s3.eu-west-1.amazonaws.com/key?versionId=3#top.",
+		},
+		{
+			name:  "regional path-style",
+			input: "https://s3.eu-west-1.amazonaws.com/eu-bucket/",
+			want:  "https://eu-bucket.s3.eu-west-1.amazonaws.com/",
+		},
+		{
+			name:  "legacy dash-style with wrong region",
+			input: "http://s3-us-east-1.amazonaws.com/west-bucket",
+			want:  "http://west-bucket.s3.us-west-2.amazonaws.com",
+		},
+		{
+			name:  "already regional",
+			input: "https://west-bucket.s3.us-west-2.amazonaws.com/key",
+			want:  "https://west-bucket.s3.us-west-2.amazonaws.com/key",
+		},
+		{
+			name: "presigned left alone",
+			input: "https://west-bucket.s3.amazonaws.com/key?X-Amz-Algorithm=AWS4-HMAC-SHA256" +
+				"&X-Amz-Credential=AKIA%2F20261016%2Fus-west-2%2Fs3%2Faws4_request&X-Amz-Date=20261016T120000Z" +
+				"&X-Amz-Expires=60&X-Amz-Signature=abc",
+			want: "https://west-bucket.s3.amazonaws.com/key?X-Amz-Algorithm=AWS4-HMAC-SHA256" +
+				"&X-Amz-Credential=AKIA%2F20261016%2Fus-west-2%2Fs3%2Faws4_request&X-Amz-Date=20261016T120000Z" +
+				"&X-Amz-Expires=60&X-Amz-Signature=abc",
+		},
+		{
+			name:  "other endpoints left alone",
+			input: "s3://west-bucket/key https://west-bucket.s3-accelerate.amazonaws.com/key https://west-bucket.s3.dualstack.us-east-1.amazonaws.com/key",
+			want:  "s3://west-bucket/key https://west-bucket.s3-accelerate.amazonaws.com/key https://west-bucket.s3.dualstack.us-east-1.amazonaws.com/key",
+		},
+		{
+			name:  "dotted bucket left alone",
+			input: "https://s3.amazonaws.com/my.dotted.bucket/key",
+			want:  "https://s3.amazonaws.com/my.dotted.bucket/key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := RewriteURLs(context.Background(), tt.input, WithHTTPClient(client))
+			if err != nil {
+				t.Fatalf("RewriteURLs() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RewriteURLs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteURLsReportsRewritesAndErrors(t *testing.T) {
+	client := &bucketRegionClient{regions: map[string]string{"west-bucket": "us-west-2"}}
+	input := "https://s3.amazonaws.com/west-bucket/a https://s3.amazonaws.com/gone-bucket/b https://west-bucket.s3.amazonaws.com/c"
+
+	got, rewrites, err := RewriteURLs(context.Background(), input, WithHTTPClient(client))
+	if !errors.Is(err, ErrBucketNotFound) {
+		t.Errorf("RewriteURLs() error = %v, want %v", err, ErrBucketNotFound)
+	}
+
+	want := "https://west-bucket.s3.us-west-2.amazonaws.com/a https://s3.amazonaws.com/gone-bucket/b https://west-bucket.s3.us-west-2.amazonaws.com/c"
+	if got != want {
+		t.Errorf("RewriteURLs() = %q, want %q", got, want)
+	}
+	if len(rewrites) != 2 || rewrites[1].Match.Offset != 78 || rewrites[1].Region != "us-west-2" {
+		t.Errorf("RewriteURLs() rewrites = %+v", rewrites)
+	}
+	if client.calls != 2 {
+		t.Errorf("HTTP client called %d times, want one lookup per bucket", client.calls)
+	}
+}
@@ -77,77 +77,66 @@ func isValidBucketName(name string) bool {
 
 // GetBucketRegionByName takes a bucket name and returns its region by constructing
 // the S3 URL and performing a HEAD request to extract the x-amz-bucket-region header.
+// WithStrategy selects DNS-based inference instead of, or in addition to, the HEAD request.
+// If the header is missing, the fallbacks set with WithFallbacks are tried.
 func GetBucketRegionByName(ctx context.Context, bucketName string, opts ...Option) (string, error) {
-	const op = "GetBucketRegionByName"
-
-	cfg := &config{
-		httpClient: http.DefaultClient,
-	}
-	for _, opt := range opts {
-		opt(cfg)
-	}
-
-	if !isValidBucketName(bucketName) {
-		return "", newError(op, bucketName, bucketName, ErrInvalidBucketName)
-	}
+	return regionOf(lookupByName(ctx, newConfig(opts), "GetBucketRegionByName", bucketName))
+}
 
+// headRegion performs a HEAD request against the bucket's global endpoint and
+// returns the region from the x-amz-bucket-region header. If the header is
+// missing, a refused request is retried against the bucket's regional
+// endpoint and the configured fallbacks are tried. The response the region
+// was found in is returned along with it.
+func headRegion(ctx context.Context, cfg *config, bucketName string) (string, Source, *response, error) {
 	url := fmt.Sprintf("https://%s.s3.amazonaws.com", bucketName)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	resp, err := send(ctx, cfg, http.MethodHead, url)
 	if err != nil {
-		return "", newError(op, bucketName, bucketName, fmt.Errorf("failed to create request: %w", err))
+		return "", 0, nil, err
 	}
 
-	resp, err := cfg.httpClient.Do(req)
-	if err != nil {
-		return "", newError(op, bucketName, bucketName, fmt.Errorf("failed to perform HEAD request: %w", err))
+	if resp.status == http.StatusNotFound {
+		return "", 0, nil, resp.error(ErrBucketNotFound)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		return "", newError(op, bucketName, bucketName, ErrBucketNotFound)
+	if region := resp.header.Get("x-amz-bucket-region"); region != "" {
+		return strings.TrimSpace(region), SourceHeader, resp, nil
 	}
 
-	region := resp.Header.Get("x-amz-bucket-region")
-	if region == "" {
-		return "", newError(op, bucketName, bucketName, ErrRegionHeaderNotFound)
+	p := &probe{ctx: ctx, cfg: cfg, bucket: bucketName, head: resp}
+	switch status := resp.status; {
+	case status == http.StatusBadRequest || status == http.StatusForbidden:
+		// The global endpoint refuses buckets in opt-in regions, and private
+		// buckets may hide the header. The error body can name the region.
+		if region, ok := p.regionalRetry(); ok {
+			return region, SourceRegionalEndpoint, p.answer, nil
+		}
+		if region, src, ok := p.fallbacks(); ok {
+			return region, src, p.answer, nil
+		}
+		return "", 0, nil, p.error(statusErrorClass(status))
+	case status >= 400:
+		return "", 0, nil, p.error(statusErrorClass(status))
 	}
 
-	return strings.TrimSpace(region), nil
+	if region, src, ok := p.fallbacks(); ok {
+		return region, src, p.answer, nil
+	}
+	return "", 0, nil, p.error(ErrRegionHeaderNotFound)
 }
 
 // GetBucketRegionFromARN extracts the bucket name from an AWS S3 ARN and returns its region.
 // Accepts ARN format: arn:aws:s3:::bucket-name or arn:aws:s3:::bucket-name/path/to/object
+// ARNs from the aws-cn and aws-us-gov partitions are accepted as well.
 func GetBucketRegionFromARN(ctx context.Context, arn string, opts ...Option) (string, error) {
-	const op = "GetBucketRegionFromARN"
-
-	bucketName := strings.TrimPrefix(arn, "arn:aws:s3:::")
-	// Remove any path after bucket name
-	if idx := strings.Index(bucketName, "/"); idx != -1 {
-		bucketName = bucketName[:idx]
-	}
-	region, err := GetBucketRegionByName(ctx, bucketName, opts...)
-	if err != nil {
-		return "", newError(op, bucketName, arn, err)
-	}
-	return region, nil
+	return regionOf(lookupARN(ctx, arn, opts))
 }
 
 // GetBucketRegionFromS3URI extracts the bucket name from an S3 URI and returns its region.
 // Accepts S3 URI format: s3://bucket-name or s3://bucket-name/path/to/object
+// The Hadoop/Spark schemes s3a:// and s3n:// are accepted as well, case-insensitively.
 func GetBucketRegionFromS3URI(ctx context.Context, uri string, opts ...Option) (string, error) {
-	const op = "GetBucketRegionFromS3URI"
-
-	bucketName := strings.TrimPrefix(uri, "s3://")
-	// Remove any path after bucket name
-	if idx := strings.Index(bucketName, "/"); idx != -1 {
-		bucketName = bucketName[:idx]
-	}
-	region, err := GetBucketRegionByName(ctx, bucketName, opts...)
-	if err != nil {
-		return "", newError(op, bucketName, uri, err)
-	}
-	return region, nil
+	return regionOf(lookupS3URI(ctx, uri, opts))
 }
 
 // GetBucketRegionFromHTTPURL extracts the bucket name from an HTTP/HTTPS URL and returns its region.
@@ -155,86 +144,38 @@ func GetBucketRegionFromS3URI(ctx context.Context, uri string, opts ...Option) (
 // - Virtual-hosted: https://bucket-name.s3.amazonaws.com/path/to/object
 // - Path-style: https://s3.amazonaws.com/bucket-name/path/to/object
 // - Path-style with region: https://s3.us-west-2.amazonaws.com/bucket-name/path/to/object
+// - PrivateLink: https://bucket-name.bucket.vpce-1a2b3c4d-5e6f.s3.us-east-1.vpce.amazonaws.com/path
+// - Console: https://s3.console.aws.amazon.com/s3/buckets/bucket-name?region=us-west-2
+// - Access point: https://my-ap-123456789012.s3-accesspoint.us-west-2.amazonaws.com/path
+// - S3 Express: https://bucket--usw2-az1--x-s3.s3express-usw2-az1.us-west-2.amazonaws.com/path
+//
+// The region of PrivateLink, access point and S3 Express endpoints is taken
+// from the hostname without a network request. With WithCNAMELookup, custom
+// domains such as https://assets.example.com/path are resolved to the bucket
+// their CNAME chain points to.
 func GetBucketRegionFromHTTPURL(ctx context.Context, url string, opts ...Option) (string, error) {
-	const op = "GetBucketRegionFromHTTPURL"
-	originalURL := url
-
-	// Remove protocol
-	url = strings.TrimPrefix(url, "https://")
-	url = strings.TrimPrefix(url, "http://")
-
-	// Get the host part (before first /)
-	host := url
-	path := ""
-	if idx := strings.Index(url, "/"); idx != -1 {
-		host = url[:idx]
-		path = url[idx+1:]
-	}
-
-	var bucketName string
-	var region string
-	var err error
-
-	// Check if this is a virtual-hosted-style URL (bucket-name.s3.amazonaws.com)
-	if strings.Contains(host, ".s3.") || strings.Contains(host, ".s3-") {
-		// Extract bucket name from host (before .s3.)
-		if idx := strings.Index(host, ".s3"); idx != -1 {
-			bucketName = host[:idx]
-			region, err = GetBucketRegionByName(ctx, bucketName, opts...)
-		}
-	} else if path != "" {
-		// Path-style URL (s3.amazonaws.com/bucket-name or s3.region.amazonaws.com/bucket-name)
-		// Extract bucket name from path (first segment)
-		bucketName = path
-		if idx := strings.Index(path, "/"); idx != -1 {
-			bucketName = path[:idx]
-		}
-		region, err = GetBucketRegionByName(ctx, bucketName, opts...)
-	} else {
-		// If we couldn't parse it, treat the host as bucket name
-		bucketName = host
-		region, err = GetBucketRegionByName(ctx, bucketName, opts...)
-	}
-
-	if err != nil {
-		return "", newError(op, bucketName, originalURL, err)
-	}
-	return region, nil
+	return regionOf(lookupHTTPURL(ctx, url, opts))
 }
 
 // GetBucketRegion is the main umbrella function that accepts any S3 identifier format
 // and automatically detects the type to extract the bucket region. Supports:
 // - Bucket name: my-bucket or my-bucket/path/to/object
-// - S3 URI: s3://my-bucket or s3://my-bucket/path/to/object
+// - S3 URI: s3://my-bucket or s3://my-bucket/path/to/object (also s3a:// and s3n://)
 // - AWS ARN: arn:aws:s3:::my-bucket or arn:aws:s3:::my-bucket/path
 // - HTTP/HTTPS URL: https://my-bucket.s3.amazonaws.com or https://my-bucket.s3.amazonaws.com/path/to/object
+//
+// Aliases set with WithAliases are expanded first, and parsers registered
+// with RegisterScheme are tried before the built-in formats. WithParseMode
+// rejects ambiguous inputs or strips decorations such as quotes and brackets.
+// Lookup returns the full result of the lookup.
 func GetBucketRegion(ctx context.Context, input string, opts ...Option) (string, error) {
-	const op = "GetBucketRegion"
-
-	// Handle AWS ARN format
-	if strings.HasPrefix(input, "arn:aws:s3:::") {
-		return GetBucketRegionFromARN(ctx, input, opts...)
-	}
-
-	// Handle S3 URI format
-	if strings.HasPrefix(input, "s3://") {
-		return GetBucketRegionFromS3URI(ctx, input, opts...)
-	}
-
-	// Handle HTTP/HTTPS URL format
-	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
-		return GetBucketRegionFromHTTPURL(ctx, input, opts...)
-	}
+	return regionOf(lookupInput(ctx, "GetBucketRegion", input, opts))
+}
 
-	// Handle plain bucket name with or without path
-	bucketName := input
-	if idx := strings.Index(input, "/"); idx != -1 {
-		bucketName = input[:idx]
-	}
-	region, err := GetBucketRegionByName(ctx, bucketName, opts...)
-	if err != nil && input != bucketName {
-		// Wrap error to include original input if it had a path
-		return "", newError(op, bucketName, input, err)
+// regionOf returns the region of a lookup result.
+func regionOf(res *Result, err error) (string, error) {
+	if err != nil {
+		return "", err
 	}
-	return region, err
+	return res.Region, nil
 }
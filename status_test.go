@@ -0,0 +1,122 @@
+package s3region
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStatusHandling(t *testing.T) {
+	optInRegion := `<Error><Code>IllegalLocationConstraintException</Code><Message>The me-south-1 location constraint is incompatible for the region specific endpoint this request was sent to.</Message></Error>`
+	accessDenied := `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`
+
+	tests := []struct {
+		name        string
+		routes      map[string]stubResponse
+		wantRegion  string
+		wantSource  Source
+		wantErr     error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name: "opt-in region retried against regional endpoint",
+			routes: map[string]stubResponse{
+				headURL:     {400, nil, ""},
+				locationURL: {400, nil, optInRegion},
+				"HEAD https://my-bucket.s3.me-south-1.amazonaws.com": {200, map[string]string{"x-amz-bucket-region": "me-south-1"}, ""},
+			},
+			wantRegion: "me-south-1",
+			wantSource: SourceRegionalEndpoint,
+		},
+		{
+			name: "regional endpoint refuses too",
+			routes: map[string]stubResponse{
+				headURL:     {400, nil, ""},
+				locationURL: {400, nil, optInRegion},
+				"HEAD https://my-bucket.s3.me-south-1.amazonaws.com": {400, nil, ""},
+			},
+			wantErr:     ErrUnexpectedStatus,
+			wantStatus:  400,
+			wantCode:    "IllegalLocationConstraintException",
+			wantMessage: "The me-south-1 location constraint is incompatible for the region specific endpoint this request was sent to.",
+		},
+		{
+			name: "private bucket without header",
+			routes: map[string]stubResponse{
+				headURL:     {403, nil, ""},
+				locationURL: {403, nil, accessDenied},
+			},
+			wantErr:     ErrAccessDenied,
+			wantStatus:  403,
+			wantCode:    "AccessDenied",
+			wantMessage: "Access Denied",
+		},
+		{
+			name: "private bucket with header",
+			routes: map[string]stubResponse{
+				headURL: {403, map[string]string{"x-amz-bucket-region": "us-east-2"}, ""},
+			},
+			wantRegion: "us-east-2",
+			wantSource: SourceHeader,
+		},
+		{
+			name:       "server error",
+			routes:     map[string]stubResponse{headURL: {500, nil, ""}},
+			wantErr:    ErrUnexpectedStatus,
+			wantStatus: 500,
+		},
+		{
+			name:       "not found",
+			routes:     map[string]stubResponse{headURL: {404, nil, ""}},
+			wantErr:    ErrBucketNotFound,
+			wantStatus: 404,
+		},
+		{
+			name:       "not found with header",
+			routes:     map[string]stubResponse{headURL: {404, map[string]string{"x-amz-bucket-region": "us-west-2"}, ""}},
+			wantErr:    ErrBucketNotFound,
+			wantStatus: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &routeClient{routes: tt.routes}
+			res, err := LookupBucketRegion(context.Background(), "my-bucket", WithHTTPClient(client))
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("LookupBucketRegion() error = %v", err)
+				}
+				if res.Region != tt.wantRegion || res.Source != tt.wantSource {
+					t.Errorf("LookupBucketRegion() = %q from %v, want %q from %v",
+						res.Region, res.Source, tt.wantRegion, tt.wantSource)
+				}
+				return
+			}
+
+			var e *Error
+			if !errors.As(err, &e) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LookupBucketRegion() error = %v, want %v", err, tt.wantErr)
+			}
+			if e.StatusCode != tt.wantStatus || e.Code != tt.wantCode || e.Message != tt.wantMessage {
+				t.Errorf("Error = %d %q %q, want %d %q %q",
+					e.StatusCode, e.Code, e.Message, tt.wantStatus, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestServerErrorSkipsFallbacks(t *testing.T) {
+	client := &routeClient{routes: map[string]stubResponse{headURL: {503, nil, ""}}}
+	_, err := GetBucketRegion(context.Background(), "s3://my-bucket/key", WithHTTPClient(client))
+
+	var e *Error
+	if !errors.As(err, &e) || e.StatusCode != 503 {
+		t.Fatalf("GetBucketRegion() error = %v, want status 503", err)
+	}
+	if len(client.requests) != 1 {
+		t.Errorf("requests = %q, want only the HEAD request", client.requests)
+	}
+}